@@ -0,0 +1,101 @@
+// Command ansible-defaults-diff compares role defaults of two Ansible collection versions.
+//
+// Each side is either a snapshot file (local or on a server) or a collection image:
+//
+//	go run ./cmd/ansible-defaults-diff \
+//	    -old-snapshot ../releases/1.2.1/stable/snapshot.json \
+//	    -new-snapshot ../releases/1.3.0/stable/snapshot.json
+//
+// Removed variables are flagged as breaking; -fail-on-breaking turns them into a non-zero exit code.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/securesign/structural-tests/test/support"
+	"github.com/securesign/structural-tests/test/support/ansible"
+)
+
+func main() {
+	oldSnapshot := flag.String("old-snapshot", "", "snapshot file of the version upgraded from")
+	newSnapshot := flag.String("new-snapshot", "", "snapshot file of the version upgraded to")
+	oldImage := flag.String("old-image", "", "collection image of the version upgraded from")
+	newImage := flag.String("new-image", "", "collection image of the version upgraded to")
+	format := flag.String("format", "text", "output format: text or json")
+	failOnBreaking := flag.Bool("fail-on-breaking", false, "exit with status 1 when breaking changes are found")
+	flag.Parse()
+
+	ctx := context.Background()
+	oldDefaults, err := loadDefaults(ctx, *oldSnapshot, *oldImage)
+	if err != nil {
+		log.Fatalf("old collection: %v", err)
+	}
+	newDefaults, err := loadDefaults(ctx, *newSnapshot, *newImage)
+	if err != nil {
+		log.Fatalf("new collection: %v", err)
+	}
+
+	changes := ansible.DiffDefaults(oldDefaults, newDefaults)
+	if err := printChanges(changes, *format); err != nil {
+		log.Fatal(err)
+	}
+	if *failOnBreaking {
+		for _, change := range changes {
+			if change.Breaking {
+				os.Exit(1)
+			}
+		}
+	}
+}
+
+func loadDefaults(ctx context.Context, snapshotFile, image string) (ansible.RoleDefaults, error) {
+	var (
+		collection *ansible.Collection
+		err        error
+	)
+	switch {
+	case snapshotFile != "" && image != "":
+		return nil, errors.New("use either a snapshot or an image, not both")
+	case snapshotFile != "":
+		snapshotData, parseErr := support.ParseSnapshotFile(snapshotFile)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		collection, err = ansible.LoadCollectionFromSnapshot(ctx, snapshotData)
+	case image != "":
+		collection, err = ansible.LoadCollectionFromImage(ctx, image)
+	default:
+		return nil, errors.New("a snapshot or an image is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ansible.LoadRoleDefaults(collection)
+}
+
+func printChanges(changes []ansible.DefaultsChange, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(changes); err != nil {
+			return fmt.Errorf("encode changes: %w", err)
+		}
+	case "text":
+		for _, change := range changes {
+			fmt.Println(change.String())
+		}
+		if len(changes) == 0 {
+			fmt.Println("no changes in role defaults")
+		}
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	return nil
+}
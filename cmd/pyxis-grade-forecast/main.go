@@ -0,0 +1,123 @@
+// Command pyxis-grade-forecast prints when Pyxis freshness grades of images change, for release planning:
+//
+//	go run ./cmd/pyxis-grade-forecast -as-of 2025-06-30 \
+//	    registry.redhat.io/openshift4/ose-cli@sha256:...
+//
+// The forecast is written as a table, JSON (-format json) or an iCalendar file (-format ical).
+// With -check it also validates the grades at the -as-of date (default now) like the acceptance tests.
+// -config reads the grades section, including per-image overrides, of a suite config; explicit flags take
+// precedence over it, and -as-of over GRADES_AS_OF.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/securesign/structural-tests/test/support"
+	"github.com/securesign/structural-tests/test/support/pyxis"
+)
+
+func main() {
+	configFile := flag.String("config", "", "suite config with a grades section, e.g. test/acceptance/rhtas/defaults.yaml")
+	asOf := flag.String("as-of", "", "evaluate at this date (YYYY-MM-DD) instead of now, e.g. the planned GA date")
+	threshold := flag.String("threshold", pyxis.DefaultGradePolicy().Threshold.String(), "worst accepted grade")
+	days := flag.Int("freshness-days", pyxis.DefaultGradePolicy().FreshnessDays, "days after -as-of checked by -check")
+	format := flag.String("format", "table", "output format: table, json or ical")
+	output := flag.String("out", "-", "output file, - for stdout")
+	check := flag.Bool("check", false, "exit with status 1 when a grade is below threshold within the window")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		log.Fatal("at least one image reference with digest is required")
+	}
+	policy, err := loadPolicy(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var flagErr error
+	flag.Visit(func(set *flag.Flag) {
+		switch set.Name {
+		case "as-of":
+			policy.AsOf = *asOf
+		case "threshold":
+			grade, ok := pyxis.ParseGrade(*threshold)
+			if !ok {
+				flagErr = fmt.Errorf("invalid threshold %q", *threshold)
+			}
+			policy.Threshold = grade
+		case "freshness-days":
+			policy.FreshnessDays = *days
+		}
+	})
+	if flagErr != nil {
+		log.Fatal(flagErr)
+	}
+	from, err := policy.EvaluationTime()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	images := make(map[string]string, flag.NArg())
+	for _, image := range flag.Args() {
+		images[image] = image
+	}
+	results, err := pyxis.FetchGradesForImages(images)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, notFound := range results.NotFound {
+		log.Printf("No grades in Pyxis for %s\n", notFound)
+	}
+
+	if err := writeForecast(*output, *format, pyxis.Forecast(results.Grades, policy, from)); err != nil {
+		log.Fatal(err)
+	}
+	if *check {
+		errs := pyxis.ValidateGrades(results.Grades, policy)
+		for _, err := range errs {
+			log.Println(err)
+		}
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+	}
+}
+
+// loadPolicy returns the grades section of the config file, or the default policy without one,
+// with GRADES_AS_OF applied.
+func loadPolicy(configFile string) (pyxis.GradePolicy, error) {
+	if configFile == "" {
+		return pyxis.DefaultGradePolicy().WithEnvAsOf(), nil
+	}
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return pyxis.GradePolicy{}, fmt.Errorf("read config: %w", err)
+	}
+	return support.GetGradePolicyFromConfig(content)
+}
+
+func writeForecast(output, format string, transitions []pyxis.GradeTransition) error {
+	var writer io.Writer = os.Stdout
+	if output != "-" {
+		file, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", output, err)
+		}
+		defer file.Close()
+		writer = file
+	}
+	switch format {
+	case "table":
+		return pyxis.WriteForecastTable(writer, transitions)
+	case "json":
+		return pyxis.WriteForecastJSON(writer, transitions)
+	case "ical":
+		return pyxis.WriteForecastICal(writer, transitions, time.Now())
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
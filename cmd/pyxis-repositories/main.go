@@ -0,0 +1,50 @@
+// Command pyxis-repositories regenerates testdata/repositories.json from the Pyxis product-listings API:
+//
+//	PYXIS_API_KEY=... go run ./cmd/pyxis-repositories -out testdata/repositories.json
+//
+// The Pyxis API root is taken from -url, PYXIS_URL or defaults to catalog.redhat.com.
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/securesign/structural-tests/test/support/pyxis"
+)
+
+func main() {
+	baseURL := flag.String("url", "", "Pyxis API root (default PYXIS_URL or "+pyxis.DefaultBaseURL+")")
+	productListingID := flag.String("product-listing", pyxis.DefaultProductListingID, "product listing id")
+	filter := flag.String("filter", pyxis.DefaultRepositoryFilter, "Pyxis filter for repositories, empty for all")
+	pageSize := flag.Int("page-size", pyxis.DefaultPageSize, "page size used when paginating")
+	output := flag.String("out", "-", "output file, - for stdout")
+	flag.Parse()
+
+	client := pyxis.NewClientFromEnv()
+	if *baseURL != "" {
+		client.BaseURL = strings.TrimRight(*baseURL, "/")
+	}
+	client.PageSize = *pageSize
+
+	list, err := client.FetchProductListingRepositories(context.Background(), *productListingID, *filter)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var writer io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		writer = file
+	}
+	if err := pyxis.WriteRepositoryList(writer, list); err != nil {
+		log.Fatal(err) //nolint:gocritic // file is flushed by the OS on exit
+	}
+}
@@ -2,13 +2,14 @@ package acceptance
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log"
+	"slices"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/securesign/structural-tests/test/support"
+	"github.com/securesign/structural-tests/test/support/ansible"
 	"github.com/securesign/structural-tests/test/support/pyxis"
 )
 
@@ -17,8 +18,14 @@ var _ = Describe("Trusted Artifact Signer Ansible", Ordered, func() {
 	var (
 		snapshotData           support.SnapshotData
 		repositories           *support.RepositoryList
+		repositoryPolicy       support.RepositoryPolicy
+		gradePolicy            pyxis.GradePolicy
+		vulnerabilityPolicy    pyxis.VulnerabilityPolicy
+		newerDigestPolicy      pyxis.NewerDigestPolicy
+		ansibleCollection      *ansible.Collection
 		ansibleFileContent     []byte
 		ansibleCollectionImage string
+		ansibleCollectionURL   string
 
 		ansibleTasImages   support.AnsibleMap
 		ansibleOtherImages support.AnsibleMap
@@ -39,36 +46,66 @@ var _ = Describe("Trusted Artifact Signer Ansible", Ordered, func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(repositories.Data).NotTo(BeEmpty(), "No images were detected in repositories file")
 
-		By("resolve ansible collection image from snapshot")
+		By("resolve ansible collection from snapshot")
 		ansibleCollectionImage = snapshotData.Others[support.AnsibleCollectionImageKey]
-		if ansibleCollectionImage != "" {
+		ansibleCollectionURL = snapshotData.Others[support.AnsibleCollectionURLKey]
+		switch {
+		case ansibleCollectionImage != "":
 			log.Printf("Using ansible collection from image: %s\n", ansibleCollectionImage)
+		case ansibleCollectionURL != "":
+			log.Printf("Using ansible collection from url: %s\n", ansibleCollectionURL)
 		}
 
 		By("check supported version")
 		version := support.GetEnv(support.EnvVersion)
-		if support.IsBeforeVersion("1.2.0") && ansibleCollectionImage == "" {
+		if support.IsBeforeVersion("1.2.0") && ansibleCollectionImage == "" && ansibleCollectionURL == "" {
 			Skip("Ansible is optional for " + version)
 		}
 
 		By("load ansible image key lists from config")
-		defaultsToUse := defaults
-		if content, err := support.GetTestConfigContent(); err == nil && len(content) > 0 {
-			defaultsToUse, err = support.MergeDefaultsConfig(defaults, content)
-			Expect(err).NotTo(HaveOccurred())
-		}
+		defaultsToUse, err := support.SuiteDefaults(defaults)
+		Expect(err).NotTo(HaveOccurred())
 		ansibleTasKeys, ansibleOtherKeys, err = support.GetAnsibleImageKeysFromConfig(defaultsToUse)
 		Expect(err).NotTo(HaveOccurred())
+		repositoryPolicy, err = support.GetRepositoryPolicyFromConfig(defaultsToUse)
+		Expect(err).NotTo(HaveOccurred())
+		gradePolicy, err = support.GetGradePolicyFromConfig(defaultsToUse)
+		Expect(err).NotTo(HaveOccurred())
+		vulnerabilityPolicy, err = support.GetVulnerabilityPolicyFromConfig(defaultsToUse)
+		Expect(err).NotTo(HaveOccurred())
+		newerDigestPolicy, err = support.GetNewerDigestPolicyFromConfig(defaultsToUse)
+		Expect(err).NotTo(HaveOccurred())
 	})
 
 	It("load ansible definition file", func() {
-		Expect(ansibleCollectionImage).NotTo(BeEmpty(), "need ansible collection image from snapshot")
+		Expect(ansibleCollectionImage+ansibleCollectionURL).NotTo(BeEmpty(), "need ansible collection image or url from snapshot")
 		var err error
-		ansibleFileContent, err = support.LoadAnsibleCollectionFromImage(context.Background(), ansibleCollectionImage, support.AnsibleCollectionSnapshotFile)
+		ansibleCollection, err = ansible.LoadCollectionFromSnapshot(context.Background(), snapshotData)
 		Expect(err).NotTo(HaveOccurred())
+		ansibleFileContent, _ = ansibleCollection.File(support.AnsibleCollectionSnapshotFile)
 		Expect(ansibleFileContent).NotTo(BeEmpty(), "Ansible definition file seems to be empty")
 	})
 
+	It("ansible collection is a well-formed Galaxy artifact", func() {
+		Expect(ansibleCollection).NotTo(BeNil(), "need ansible collection loaded from image")
+		findings := ansible.ValidateIntegrity(ansibleCollection, ansible.Metadata{
+			Namespace: ansible.CollectionNamespace,
+			Name:      ansible.CollectionName,
+			Version:   support.GetVersion(),
+		})
+		if len(findings) > 0 {
+			Fail(fmt.Sprintf("Ansible collection integrity errors found (%d):\n%s", len(findings), ansible.FormatFindings(findings)))
+		}
+	})
+
+	It("ansible role argument specs match role defaults", func() {
+		Expect(ansibleCollection).NotTo(BeNil(), "need ansible collection loaded from image")
+		findings := ansible.ValidateArgumentSpecs(ansibleCollection)
+		if len(findings) > 0 {
+			Fail(fmt.Sprintf("Ansible argument_specs and defaults mismatches found (%d):\n%s", len(findings), ansible.FormatFindings(findings)))
+		}
+	})
+
 	It("get and parse ansible images definition file", func() {
 		ansibleAllImages, err := support.MapAnsibleImages(ansibleFileContent)
 		Expect(err).NotTo(HaveOccurred())
@@ -78,13 +115,14 @@ var _ = Describe("Trusted Artifact Signer Ansible", Ordered, func() {
 		Expect(ansibleOtherImages).NotTo(BeEmpty())
 		support.LogMap(fmt.Sprintf("Ansible TAS images (%d):", len(ansibleTasImages)), ansibleTasImages)
 		support.LogMap(fmt.Sprintf("Ansible other images (%d):", len(ansibleOtherImages)), ansibleOtherImages)
+		support.RecordUsedImages(support.UsedImagesAnsible, support.GetMapValues(ansibleAllImages)...)
 	})
 
 	It("ansible TAS images are listed in registry.redhat.io", func() {
 		var errs []error
 		for _, ansibleImage := range ansibleTasImages {
-			if repositories.FindByImage(ansibleImage) == nil {
-				errs = append(errs, fmt.Errorf("%w: %s", errors.New("not found in registry"), ansibleImage))
+			if err := repositories.CheckImage(ansibleImage, repositoryPolicy); err != nil {
+				errs = append(errs, err)
 			}
 		}
 		Expect(errs).To(BeEmpty())
@@ -135,14 +173,56 @@ var _ = Describe("Trusted Artifact Signer Ansible", Ordered, func() {
 		Expect(ansibleTasImages).To(HaveLen(len(hashesCounts)))
 	})
 
+	It("ansible collection does not reference unlisted images", func() {
+		Expect(ansibleCollection).NotTo(BeNil(), "need ansible collection loaded from image")
+		covered := make(map[string]string)
+		for _, imageKey := range append(slices.Clone(ansibleTasKeys), ansibleOtherKeys...) {
+			if image, ok := ansibleTasImages[imageKey]; ok {
+				covered[imageKey] = image
+			}
+			if image, ok := ansibleOtherImages[imageKey]; ok {
+				covered[imageKey] = image
+			}
+		}
+		findings := ansible.ScanImageReferences(ansibleCollection, covered)
+		if len(findings) > 0 {
+			Fail(fmt.Sprintf("Unlisted image references found in ansible collection (%d):\n%s", len(findings), ansible.FormatFindings(findings)))
+		}
+	})
+
 	It("other images have acceptable grades", func() {
 		Expect(ansibleOtherImages).NotTo(BeEmpty(), "No other images found to check grades for")
 		results, err := pyxis.FetchGradesForImages(ansibleOtherImages)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(results.NotFound).To(BeEmpty(), "Some ansible other images were not found in Pyxis")
 		Expect(results.Grades).NotTo(BeEmpty())
-		errs := pyxis.ValidateGrades(results.Grades, pyxis.GradeB, 7)
+		errs := pyxis.ValidateGrades(results.Grades, gradePolicy)
+		expected := support.GetExpectedGradeArchitectures(context.Background(), ansibleOtherImages, gradePolicy.Platforms)
+		errs = append(errs, pyxis.ValidateArchitectureCoverage(results.Grades, expected)...)
 		Expect(errs).To(BeEmpty(), "Some ansible other images have unacceptable grades")
 	})
 
+	It("other images have no fixable important vulnerabilities", func() {
+		Expect(ansibleOtherImages).NotTo(BeEmpty(), "No other images found to check vulnerabilities for")
+		results, err := pyxis.FetchGradesForImages(ansibleOtherImages)
+		Expect(err).NotTo(HaveOccurred())
+		vulnerabilities, err := pyxis.FetchVulnerabilitiesForImages(results.Grades)
+		Expect(err).NotTo(HaveOccurred())
+		log.Printf("Ansible other images vulnerabilities:\n%s", pyxis.SummarizeVulnerabilities(vulnerabilities))
+		errs := pyxis.ValidateVulnerabilities(vulnerabilities, vulnerabilityPolicy)
+		Expect(errs).To(BeEmpty(), "Some ansible other images have fixable vulnerabilities")
+	})
+
+	It("other images are pinned to the latest digest of their stream", func() {
+		Expect(ansibleOtherImages).NotTo(BeEmpty(), "No other images found to check digests for")
+		newer, err := pyxis.FindNewerDigests(ansibleOtherImages, newerDigestPolicy)
+		Expect(err).NotTo(HaveOccurred())
+		for _, digest := range newer {
+			log.Printf("Newer digest available: %s\n", digest)
+		}
+		if newerDigestPolicy.Fail {
+			Expect(newer).To(BeEmpty(), "Some ansible other images have a newer digest available")
+		}
+	})
+
 })
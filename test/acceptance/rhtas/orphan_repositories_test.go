@@ -0,0 +1,41 @@
+package acceptance
+
+import (
+	"fmt"
+	"log"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/securesign/structural-tests/test/support"
+)
+
+// Repositories of the product not referenced by any operator, ansible, FBC or snapshot image,
+// usually a retired component needing EOL handling or a new one missing from the config.
+// CLI images are snapshot images, they are covered by orphans.snapshotRepositories.
+var _ = AfterSuite(func() {
+	if !support.HasUsedImages(support.UsedImagesOperator) || !support.HasUsedImages(support.UsedImagesAnsible) ||
+		!support.HasUsedImages(support.UsedImagesFBC) {
+		log.Println("Orphan repository report skipped, operator, Ansible and FBC images were not collected")
+		return
+	}
+	defaultsData, err := support.SuiteDefaults(defaults)
+	Expect(err).NotTo(HaveOccurred())
+	policy, err := support.GetRepositoryPolicyFromConfig(defaultsData)
+	Expect(err).NotTo(HaveOccurred())
+	repositories, err := support.LoadRepositoryList()
+	Expect(err).NotTo(HaveOccurred())
+	snapshotData, err := support.ParseSnapshotData()
+	Expect(err).NotTo(HaveOccurred())
+	support.RecordSnapshotImages(snapshotData, policy.Orphans.SnapshotRepositories)
+
+	orphans := repositories.OrphanRepositories(support.UsedImages(), policy.Orphans)
+	if len(orphans) == 0 {
+		log.Println("No orphan repositories found")
+		return
+	}
+	report := fmt.Sprintf("Repositories not used by any image (%d):\n%s", len(orphans), support.FormatOrphanReport(orphans))
+	log.Print(report)
+	if policy.Orphans.Fail {
+		Fail(report)
+	}
+})
@@ -17,6 +17,11 @@ func ParseSnapshotData() (SnapshotData, error) {
 	if snapshotFileName == "" {
 		return SnapshotData{}, fmt.Errorf("snapshot file name must be set. Use %s env variable for that", EnvReleasesSnapshotFile)
 	}
+	return ParseSnapshotFile(snapshotFileName)
+}
+
+// ParseSnapshotFile parses the given snapshot file, which can be local or on a server (github).
+func ParseSnapshotFile(snapshotFileName string) (SnapshotData, error) {
 	content, err := GetFileContent(snapshotFileName)
 	if err != nil {
 		return SnapshotData{}, err
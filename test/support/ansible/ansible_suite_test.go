@@ -0,0 +1,13 @@
+package ansible_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAnsible(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Ansible Suite")
+}
@@ -0,0 +1,158 @@
+package ansible
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/securesign/structural-tests/test/support"
+	"gopkg.in/yaml.v3"
+)
+
+const CheckArgumentSpecs = "argument-specs"
+
+// specOption is a top-level option of a role entry point in meta/argument_specs.yml.
+type specOption struct {
+	Type       string `yaml:"type"`
+	Required   bool   `yaml:"required"`
+	HasDefault bool   `yaml:"-"`
+	Line       int    `yaml:"-"`
+}
+
+// yamlEntry is one key/value pair of a YAML mapping, with the line number of the key.
+type yamlEntry struct {
+	Key   string
+	Value *yaml.Node
+	Line  int
+}
+
+// ValidateArgumentSpecs checks every role that ships both defaults/main.yml and meta/argument_specs.yml.
+// Every *_image default must be declared in the argument specs with type str, and every option
+// declared in the argument specs must have a default unless it is marked required.
+func ValidateArgumentSpecs(collection *Collection) []Finding {
+	var findings []Finding
+	for _, role := range roleNames(collection) {
+		defaultsFile := path.Join("roles", role, "defaults", "main.yml")
+		specsFile := path.Join("roles", role, "meta", "argument_specs.yml")
+		defaultsContent, hasDefaults := collection.File(defaultsFile)
+		specsContent, hasSpecs := collection.File(specsFile)
+		if !hasDefaults || !hasSpecs {
+			continue
+		}
+		defaults, err := parseMapping(defaultsContent)
+		if err != nil {
+			findings = append(findings, Finding{Check: CheckArgumentSpecs, File: defaultsFile, Message: err.Error()})
+			continue
+		}
+		options, err := parseSpecOptions(specsContent)
+		if err != nil {
+			findings = append(findings, Finding{Check: CheckArgumentSpecs, File: specsFile, Message: err.Error()})
+			continue
+		}
+		findings = append(findings, compareSpecs(defaultsFile, specsFile, defaults, options)...)
+	}
+	return findings
+}
+
+func compareSpecs(defaultsFile, specsFile string, defaults []yamlEntry, options map[string]specOption) []Finding {
+	var findings []Finding
+	defaulted := make(map[string]bool, len(defaults))
+	for _, entry := range defaults {
+		defaulted[entry.Key] = true
+		if !strings.HasSuffix(entry.Key, "_image") {
+			continue
+		}
+		option, declared := options[entry.Key]
+		switch {
+		case !declared:
+			findings = append(findings, Finding{Check: CheckArgumentSpecs, File: defaultsFile, Line: entry.Line,
+				Message: entry.Key + ": not declared in " + specsFile})
+		case option.Type != "" && option.Type != "str": // ansible defaults the option type to str
+			findings = append(findings, Finding{Check: CheckArgumentSpecs, File: specsFile, Line: option.Line,
+				Message: fmt.Sprintf("%s: type is %q, expected \"str\"", entry.Key, option.Type)})
+		}
+	}
+	for _, name := range support.GetMapKeysSorted(options) {
+		option := options[name]
+		if option.Required || option.HasDefault || defaulted[name] {
+			continue
+		}
+		findings = append(findings, Finding{Check: CheckArgumentSpecs, File: specsFile, Line: option.Line,
+			Message: name + ": no default in " + defaultsFile + " and not marked required"})
+	}
+	return findings
+}
+
+// roleNames returns the names of all roles found in the collection (roles/<name>/...).
+func roleNames(collection *Collection) []string {
+	var roles []string
+	seen := make(map[string]bool)
+	for _, name := range collection.FileNames() {
+		parts := strings.Split(name, "/")
+		const minRolePathParts = 3 // roles/<name>/<file>
+		if len(parts) < minRolePathParts || parts[0] != "roles" || seen[parts[1]] {
+			continue
+		}
+		seen[parts[1]] = true
+		roles = append(roles, parts[1])
+	}
+	return roles
+}
+
+// parseSpecOptions merges top-level options of all entry points declared in argument_specs.
+func parseSpecOptions(content []byte) (map[string]specOption, error) {
+	root, err := parseMapping(content)
+	if err != nil {
+		return nil, err
+	}
+	options := make(map[string]specOption)
+	for _, section := range root {
+		if section.Key != "argument_specs" {
+			continue
+		}
+		for _, entryPoint := range mappingEntries(section.Value) {
+			for _, field := range mappingEntries(entryPoint.Value) {
+				if field.Key != "options" {
+					continue
+				}
+				for _, optionEntry := range mappingEntries(field.Value) {
+					var option specOption
+					if err := optionEntry.Value.Decode(&option); err != nil {
+						return nil, fmt.Errorf("decode option %s: %w", optionEntry.Key, err)
+					}
+					for _, attribute := range mappingEntries(optionEntry.Value) {
+						if attribute.Key == "default" {
+							option.HasDefault = true
+						}
+					}
+					option.Line = optionEntry.Line
+					options[optionEntry.Key] = option
+				}
+			}
+		}
+	}
+	return options, nil
+}
+
+func parseMapping(content []byte) ([]yamlEntry, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("cannot parse: %w", err)
+	}
+	if len(document.Content) == 0 {
+		return nil, nil
+	}
+	return mappingEntries(document.Content[0]), nil
+}
+
+func mappingEntries(node *yaml.Node) []yamlEntry {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	const pairSize = 2 // key, value
+	entries := make([]yamlEntry, 0, len(node.Content)/pairSize)
+	for i := 0; i+1 < len(node.Content); i += pairSize {
+		entries = append(entries, yamlEntry{Key: node.Content[i].Value, Value: node.Content[i+1], Line: node.Content[i].Line})
+	}
+	return entries
+}
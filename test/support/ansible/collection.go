@@ -0,0 +1,100 @@
+package ansible
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/securesign/structural-tests/test/support"
+)
+
+// Collection is an in-memory view of a Galaxy collection archive (redhat-artifact_signer-*.tar.gz).
+// File names are relative to the collection root, e.g. roles/tas_single_node/defaults/main.yml.
+type Collection struct {
+	files map[string][]byte
+	names []string
+}
+
+// LoadCollection reads every regular file of a gzipped collection archive into memory.
+func LoadCollection(archive []byte) (*Collection, error) {
+	gzReader, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("gunzip collection archive: %w", err)
+	}
+	defer gzReader.Close()
+
+	collection := &Collection{files: make(map[string][]byte)}
+	tarReader := tar.NewReader(gzReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read collection archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, fmt.Errorf("read %s from collection archive: %w", header.Name, err)
+		}
+		name := strings.TrimPrefix(header.Name, "./")
+		collection.files[name] = content
+		collection.names = append(collection.names, name)
+	}
+	if len(collection.names) == 0 {
+		return nil, errors.New("collection archive does not contain any files")
+	}
+	slices.Sort(collection.names)
+	return collection, nil
+}
+
+// LoadCollectionFromImage extracts the collection archive from the image and loads it.
+func LoadCollectionFromImage(ctx context.Context, imageRef string) (*Collection, error) {
+	archive, err := support.GetAnsibleCollectionArchiveFromImage(ctx, imageRef)
+	if err != nil {
+		return nil, err
+	}
+	return LoadCollection(archive)
+}
+
+// LoadCollectionFromURL downloads (or reads locally) a legacy collection artifact, verifies its
+// sha256 and loads the collection tarball it contains.
+func LoadCollectionFromURL(url, sha256 string) (*Collection, error) {
+	archive, err := support.GetAnsibleCollectionArchiveFromURL(url, sha256)
+	if err != nil {
+		return nil, err
+	}
+	return LoadCollection(archive)
+}
+
+// LoadCollectionFromSnapshot loads the collection referenced by the snapshot, preferring the
+// image form and falling back to the legacy url+sha256 form.
+func LoadCollectionFromSnapshot(ctx context.Context, snapshotData support.SnapshotData) (*Collection, error) {
+	if image := snapshotData.Others[support.AnsibleCollectionImageKey]; image != "" {
+		return LoadCollectionFromImage(ctx, image)
+	}
+	if url := snapshotData.Others[support.AnsibleCollectionURLKey]; url != "" {
+		return LoadCollectionFromURL(url, snapshotData.Others[support.AnsibleCollectionSHA256Key])
+	}
+	return nil, errors.New("snapshot does not reference an ansible collection image or url")
+}
+
+// FileNames returns the sorted names of all regular files in the collection.
+func (c *Collection) FileNames() []string {
+	return slices.Clone(c.names)
+}
+
+// File returns the content of the named file and whether it exists.
+func (c *Collection) File(name string) ([]byte, bool) {
+	content, ok := c.files[name]
+	return content, ok
+}
@@ -0,0 +1,112 @@
+package ansible
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/securesign/structural-tests/test/support"
+	"gopkg.in/yaml.v3"
+)
+
+// ChangeType describes how a role variable changed between two collection versions.
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+// RoleDefaults maps role name to the variables declared in its defaults/main.yml.
+type RoleDefaults map[string]map[string]interface{}
+
+// DefaultsChange is one entry of a role defaults diff. Removed variables are breaking,
+// because inventories that set them are silently ignored after the upgrade.
+type DefaultsChange struct {
+	Role     string      `json:"role"`
+	Variable string      `json:"variable"`
+	Change   ChangeType  `json:"change"`
+	Old      interface{} `json:"old,omitempty"`
+	New      interface{} `json:"new,omitempty"`
+	Image    bool        `json:"image"`
+	Breaking bool        `json:"breaking"`
+}
+
+func (c DefaultsChange) String() string {
+	marker := ""
+	if c.Breaking {
+		marker = " [BREAKING]"
+	}
+	switch c.Change {
+	case ChangeAdded:
+		return fmt.Sprintf("%s: + %s = %s%s", c.Role, c.Variable, formatValue(c.New), marker)
+	case ChangeRemoved:
+		return fmt.Sprintf("%s: - %s (was %s)%s", c.Role, c.Variable, formatValue(c.Old), marker)
+	default:
+		return fmt.Sprintf("%s: ~ %s %s -> %s%s", c.Role, c.Variable, formatValue(c.Old), formatValue(c.New), marker)
+	}
+}
+
+// LoadRoleDefaults parses roles/<role>/defaults/main.yml of every role in the collection.
+func LoadRoleDefaults(collection *Collection) (RoleDefaults, error) {
+	defaults := make(RoleDefaults)
+	for _, role := range roleNames(collection) {
+		content, ok := collection.File(path.Join("roles", role, "defaults", "main.yml"))
+		if !ok {
+			continue
+		}
+		variables := make(map[string]interface{})
+		if err := yaml.Unmarshal(content, &variables); err != nil {
+			return nil, fmt.Errorf("parse defaults of role %s: %w", role, err)
+		}
+		defaults[role] = variables
+	}
+	return defaults, nil
+}
+
+// DiffDefaults compares role defaults of two collection versions, ordered by role and variable.
+func DiffDefaults(oldDefaults, newDefaults RoleDefaults) []DefaultsChange {
+	var changes []DefaultsChange
+	roles := support.GetMapKeysSorted(mergeKeys(oldDefaults, newDefaults))
+	for _, role := range roles {
+		oldVars, newVars := oldDefaults[role], newDefaults[role]
+		for _, variable := range support.GetMapKeysSorted(mergeKeys(oldVars, newVars)) {
+			oldValue, inOld := oldVars[variable]
+			newValue, inNew := newVars[variable]
+			change := DefaultsChange{Role: role, Variable: variable, Old: oldValue, New: newValue, Image: strings.HasSuffix(variable, "image")}
+			switch {
+			case !inOld:
+				change.Change = ChangeAdded
+			case !inNew:
+				change.Change = ChangeRemoved
+				change.Breaking = true
+			case !reflect.DeepEqual(oldValue, newValue):
+				change.Change = ChangeChanged
+			default:
+				continue
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func mergeKeys[V any](first, second map[string]V) map[string]bool {
+	keys := make(map[string]bool, len(first)+len(second))
+	for key := range first {
+		keys[key] = true
+	}
+	for key := range second {
+		keys[key] = true
+	}
+	return keys
+}
+
+func formatValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", value)
+}
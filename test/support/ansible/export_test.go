@@ -0,0 +1,6 @@
+package ansible
+
+// ScanFile exports scanFile to the tests.
+func ScanFile(name string, content []byte, covered map[string]string) []Finding {
+	return scanFile(name, content, newImageCoverage(covered))
+}
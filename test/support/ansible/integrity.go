@@ -0,0 +1,167 @@
+package ansible
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/securesign/structural-tests/test/support"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CollectionNamespace = "redhat"
+	CollectionName      = "artifact_signer"
+
+	ManifestFileName = "MANIFEST.json"
+	FilesFileName    = "FILES.json"
+	RuntimeFileName  = "meta/runtime.yml"
+
+	CheckManifest = "manifest"
+	CheckFiles    = "files"
+	CheckRuntime  = "runtime"
+)
+
+// Metadata is the expected identity of the collection as declared in MANIFEST.json.
+// Version is required, an empty Version is reported as a finding instead of skipping the comparison.
+type Metadata struct {
+	Namespace string
+	Name      string
+	Version   string
+}
+
+type collectionManifest struct {
+	CollectionInfo struct {
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+		Version   string `json:"version"`
+	} `json:"collection_info"` //nolint:tagliatelle // Galaxy uses snake_case
+	FileManifestFile fileEntry `json:"file_manifest_file"` //nolint:tagliatelle // Galaxy uses snake_case
+}
+
+type fileEntry struct {
+	Name         string `json:"name"`
+	Ftype        string `json:"ftype"`
+	ChksumType   string `json:"chksum_type"`   //nolint:tagliatelle // Galaxy uses snake_case
+	ChksumSHA256 string `json:"chksum_sha256"` //nolint:tagliatelle // Galaxy uses snake_case
+}
+
+type collectionFiles struct {
+	Files []fileEntry `json:"files"`
+}
+
+type collectionRuntime struct {
+	RequiresAnsible string `yaml:"requires_ansible"` //nolint:tagliatelle // Ansible uses snake_case
+}
+
+// ValidateIntegrity checks that the collection is a well-formed Galaxy artifact: MANIFEST.json
+// identifies the expected collection, FILES.json checksums match the archive content and
+// meta/runtime.yml declares requires_ansible.
+func ValidateIntegrity(collection *Collection, expected Metadata) []Finding {
+	var findings []Finding
+	findings = append(findings, validateManifest(collection, expected)...)
+	findings = append(findings, validateFiles(collection)...)
+	findings = append(findings, validateRuntime(collection)...)
+	return findings
+}
+
+func validateManifest(collection *Collection, expected Metadata) []Finding {
+	manifest, finding := readManifest(collection)
+	if finding != nil {
+		return []Finding{*finding}
+	}
+	var findings []Finding
+	if expected.Version == "" {
+		findings = append(findings, Finding{
+			Check:   CheckManifest,
+			File:    ManifestFileName,
+			Message: "collection_info.version cannot be checked, VERSION is not set",
+		})
+	}
+	info := manifest.CollectionInfo
+	actual := map[string]string{"namespace": info.Namespace, "name": info.Name, "version": info.Version}
+	wanted := map[string]string{"namespace": expected.Namespace, "name": expected.Name, "version": expected.Version}
+	for _, field := range []string{"namespace", "name", "version"} {
+		if wanted[field] != "" && actual[field] != wanted[field] {
+			findings = append(findings, Finding{
+				Check:   CheckManifest,
+				File:    ManifestFileName,
+				Message: fmt.Sprintf("collection_info.%s is %q, expected %q", field, actual[field], wanted[field]),
+			})
+		}
+	}
+	return findings
+}
+
+func readManifest(collection *Collection) (collectionManifest, *Finding) {
+	var manifest collectionManifest
+	content, ok := collection.File(ManifestFileName)
+	if !ok {
+		return manifest, &Finding{Check: CheckManifest, File: ManifestFileName, Message: "missing"}
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return manifest, &Finding{Check: CheckManifest, File: ManifestFileName, Message: fmt.Sprintf("cannot parse: %v", err)}
+	}
+	return manifest, nil
+}
+
+func validateFiles(collection *Collection) []Finding {
+	content, ok := collection.File(FilesFileName)
+	if !ok {
+		return []Finding{{Check: CheckFiles, File: FilesFileName, Message: "missing"}}
+	}
+	var findings []Finding
+	if manifest, finding := readManifest(collection); finding == nil {
+		if actual := support.SHA256Hex(content); actual != manifest.FileManifestFile.ChksumSHA256 {
+			findings = append(findings, Finding{
+				Check:   CheckFiles,
+				File:    FilesFileName,
+				Message: fmt.Sprintf("sha256 is %s, MANIFEST.json declares %s", actual, manifest.FileManifestFile.ChksumSHA256),
+			})
+		}
+	}
+
+	var files collectionFiles
+	if err := json.Unmarshal(content, &files); err != nil {
+		return append(findings, Finding{Check: CheckFiles, File: FilesFileName, Message: fmt.Sprintf("cannot parse: %v", err)})
+	}
+	listed := map[string]bool{ManifestFileName: true, FilesFileName: true}
+	for _, entry := range files.Files {
+		if entry.Ftype != "file" {
+			continue
+		}
+		listed[entry.Name] = true
+		fileContent, exists := collection.File(entry.Name)
+		if !exists {
+			findings = append(findings, Finding{Check: CheckFiles, File: entry.Name, Message: "listed in FILES.json but missing in archive"})
+			continue
+		}
+		if actual := support.SHA256Hex(fileContent); actual != entry.ChksumSHA256 {
+			findings = append(findings, Finding{
+				Check:   CheckFiles,
+				File:    entry.Name,
+				Message: fmt.Sprintf("sha256 is %s, FILES.json declares %s", actual, entry.ChksumSHA256),
+			})
+		}
+	}
+	for _, name := range collection.FileNames() {
+		if !listed[name] {
+			findings = append(findings, Finding{Check: CheckFiles, File: name, Message: "present in archive but not listed in FILES.json"})
+		}
+	}
+	return findings
+}
+
+func validateRuntime(collection *Collection) []Finding {
+	content, ok := collection.File(RuntimeFileName)
+	if !ok {
+		return []Finding{{Check: CheckRuntime, File: RuntimeFileName, Message: "missing"}}
+	}
+	var runtime collectionRuntime
+	if err := yaml.Unmarshal(content, &runtime); err != nil {
+		return []Finding{{Check: CheckRuntime, File: RuntimeFileName, Message: fmt.Sprintf("cannot parse: %v", err)}}
+	}
+	if runtime.RequiresAnsible == "" {
+		return []Finding{{Check: CheckRuntime, File: RuntimeFileName, Message: "requires_ansible is not declared"}}
+	}
+	return nil
+}
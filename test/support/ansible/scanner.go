@@ -0,0 +1,143 @@
+package ansible
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Finding is a single problem detected in the collection, located by file and line (0 when unknown).
+type Finding struct {
+	Check   string
+	File    string
+	Line    int
+	Message string
+}
+
+func (f Finding) String() string {
+	if f.Line > 0 {
+		return fmt.Sprintf("[%s] %s:%d: %s", f.Check, f.File, f.Line, f.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", f.Check, f.File, f.Message)
+}
+
+// FormatFindings renders findings one per line, suitable for a test failure message.
+func FormatFindings(findings []Finding) string {
+	var report strings.Builder
+	for _, finding := range findings {
+		report.WriteString("  ")
+		report.WriteString(finding.String())
+		report.WriteString("\n")
+	}
+	return report.String()
+}
+
+const CheckImageReference = "image-reference"
+
+// imageReferenceRegexp matches registry/repository references (group 1) with an optional digest, tag or Jinja templated
+// tag (group 2), e.g. registry.redhat.io/rhtas/rekor-server-rhel9@sha256:..., quay.io/org/image:1.0,
+// registry.redhat.io/rhtas/cli:{{ tas_version }} or an untagged registry.redhat.io/rhtas/cli.
+var imageReferenceRegexp = regexp.MustCompile(
+	`(?:^|[^\w./:-])((?:[a-z0-9-]+\.)+[a-z]{2,}(?::\d+)?/[a-z0-9._-]+(?:/[a-z0-9._-]+)*)` +
+		`(@sha256:[a-f0-9]{64}|:(?:[\w.-]|\{\{[^}]*\}\})+)?`)
+
+var yamlKeyRegexp = regexp.MustCompile(`^\s*(?:-\s+)?([\w.-]+)\s*:`)
+
+// ScanImageReferences walks YAML files and templates of the collection and reports every
+// container image reference whose value is not one of the covered images.
+// covered maps the configured image keys (imageKeys and otherImageKeys) to their default values.
+func ScanImageReferences(collection *Collection, covered map[string]string) []Finding {
+	coverage := newImageCoverage(covered)
+	var findings []Finding
+	for _, name := range collection.FileNames() {
+		if !isScannable(name) {
+			continue
+		}
+		content, _ := collection.File(name)
+		if bytes.IndexByte(content, 0) != -1 {
+			continue // binary file
+		}
+		findings = append(findings, scanFile(name, content, coverage)...)
+	}
+	return findings
+}
+
+// imageCoverage holds the covered image references and their repositories.
+type imageCoverage struct {
+	images       map[string]bool
+	repositories map[string]bool
+}
+
+func newImageCoverage(covered map[string]string) imageCoverage {
+	coverage := imageCoverage{images: make(map[string]bool), repositories: make(map[string]bool)}
+	for _, image := range covered {
+		coverage.images[image] = true
+		if match := imageReferenceRegexp.FindStringSubmatch(image); match != nil {
+			coverage.repositories[match[1]] = true
+		}
+	}
+	return coverage
+}
+
+// covers reports whether a reference is covered. A reference pinned by digest or tag must be a covered image. The
+// value of a templated or missing tag is unknown, so its repository must be the repository of a covered image, or
+// for an untagged reference the namespace of one (e.g. registry.redhat.io/rhtas as a registry prefix variable).
+func (c imageCoverage) covers(repository, suffix string) bool {
+	switch {
+	case suffix == "":
+		for covered := range c.repositories {
+			if covered == repository || strings.HasPrefix(covered, repository+"/") {
+				return true
+			}
+		}
+		return false
+	case strings.Contains(suffix, "{{"):
+		return c.repositories[repository]
+	default:
+		return c.images[repository+suffix]
+	}
+}
+
+func isScannable(name string) bool {
+	switch path.Ext(name) {
+	case ".yml", ".yaml", ".j2":
+		return true
+	}
+	return slices.Contains(strings.Split(path.Dir(name), "/"), "templates")
+}
+
+func scanFile(name string, content []byte, coverage imageCoverage) []Finding {
+	var findings []Finding
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), len(content)+1)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		for _, match := range imageReferenceRegexp.FindAllStringSubmatch(line, -1) {
+			repository, suffix := match[1], strings.TrimRight(match[2], ".")
+			if suffix == "" {
+				repository = strings.TrimRight(repository, ".")
+			}
+			if coverage.covers(repository, suffix) {
+				continue
+			}
+			image := repository + suffix
+			message := "image " + image + " is not covered by configured image keys"
+			if key := yamlKeyRegexp.FindStringSubmatch(line); key != nil {
+				message = fmt.Sprintf("%s (key %s)", message, key[1])
+			}
+			findings = append(findings, Finding{
+				Check:   CheckImageReference,
+				File:    name,
+				Line:    lineNumber,
+				Message: message,
+			})
+		}
+	}
+	return findings
+}
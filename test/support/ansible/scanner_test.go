@@ -0,0 +1,55 @@
+package ansible_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/securesign/structural-tests/test/support/ansible"
+)
+
+var rekorImage = "registry.redhat.io/rhtas/rekor-server-rhel9@sha256:" + strings.Repeat("a", 64) //nolint:gochecknoglobals // test fixture
+
+var _ = Describe("Image reference scanner", func() {
+	covered := map[string]string{"rekor-server-image": rekorImage}
+
+	DescribeTable("reports image references not covered by the image keys",
+		func(content string, expected ...string) {
+			var messages []string
+			for _, finding := range ansible.ScanFile("roles/tas_single_node/defaults/main.yml", []byte(content), covered) {
+				Expect(finding.Check).To(Equal(ansible.CheckImageReference))
+				messages = append(messages, finding.Message)
+			}
+			Expect(messages).To(ConsistOf(expected))
+		},
+		Entry("covered digest", "tas_single_node_rekor_server_image: "+rekorImage),
+		Entry("other digest of a covered repository", "rekor: registry.redhat.io/rhtas/rekor-server-rhel9@sha256:"+strings.Repeat("b", 64),
+			"image registry.redhat.io/rhtas/rekor-server-rhel9@sha256:"+strings.Repeat("b", 64)+
+				" is not covered by configured image keys (key rekor)"),
+		Entry("uncovered tag", "  - image: quay.io/org/image:1.0",
+			"image quay.io/org/image:1.0 is not covered by configured image keys (key image)"),
+		Entry("tag at the end of a sentence", "# pulls registry.example.com/ns/tool:v2.",
+			"image registry.example.com/ns/tool:v2 is not covered by configured image keys"),
+		Entry("templated tag of a covered repository", "image: registry.redhat.io/rhtas/rekor-server-rhel9:{{ tas_version }}"),
+		Entry("templated tag of an uncovered repository", "image: registry.redhat.io/rhtas/trillian-db:{{ tas_version }}",
+			"image registry.redhat.io/rhtas/trillian-db:{{ tas_version }} is not covered by configured image keys (key image)"),
+		Entry("partly templated tag", `image: "registry.redhat.io/rhtas/cli:v{{ tas_version }}-1"`,
+			"image registry.redhat.io/rhtas/cli:v{{ tas_version }}-1 is not covered by configured image keys (key image)"),
+		Entry("untagged covered repository", "repository: registry.redhat.io/rhtas/rekor-server-rhel9"),
+		Entry("untagged namespace of a covered repository", "tas_registry: registry.redhat.io/rhtas"),
+		Entry("untagged uncovered repository", "{% set image = 'quay.io/org/untagged' %}",
+			"image quay.io/org/untagged is not covered by configured image keys"),
+		Entry("URL with a scheme", "homepage: https://github.com/securesign/artifact-signer-ansible"),
+		Entry("several references on one line", "images: [quay.io/a/b:1, "+rekorImage+", quay.io/c/d:{{ v }}]",
+			"image quay.io/a/b:1 is not covered by configured image keys (key images)",
+			"image quay.io/c/d:{{ v }} is not covered by configured image keys (key images)"),
+	)
+
+	It("locates findings by line", func() {
+		findings := ansible.ScanFile("roles/tas_single_node/templates/pod.j2",
+			[]byte("apiVersion: v1\nkind: Pod\n  image: quay.io/org/image:{{ tag }}\n"), covered)
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].String()).To(Equal("[image-reference] roles/tas_single_node/templates/pod.j2:3: " +
+			"image quay.io/org/image:{{ tag }} is not covered by configured image keys (key image)"))
+	})
+})
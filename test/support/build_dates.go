@@ -0,0 +1,186 @@
+package support
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	buildDateLabel = "build-date"
+
+	defaultMaxBuildAgeDays = 90
+	defaultRebuildHours    = 24
+	releaseDateLayout      = time.DateOnly
+)
+
+// buildDateLayouts are formats of the build-date label, with and without time zone.
+//
+//nolint:gochecknoglobals // read-only list of layouts
+var buildDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05"}
+
+// BuildDatePolicy configures build-date checks of snapshot images (buildDates section of the suite config).
+type BuildDatePolicy struct {
+	// MaxAgeDays is the maximal age of an image at the release date, 0 disables the check.
+	MaxAgeDays int `yaml:"maxAgeDays"`
+	// ReleaseDate (YYYY-MM-DD) the age is computed at, default today.
+	ReleaseDate string `yaml:"releaseDate"`
+	// BuiltAfter lists images that must be built after the image they reference.
+	BuiltAfter []BuildOrder `yaml:"builtAfter"`
+	// RebuildHours is the build-date spread of images with one vcs-ref reported as a rebuild.
+	RebuildHours int `yaml:"rebuildHours"`
+}
+
+// BuildOrder requires Images (image keys, path.Match patterns) to be built after the Reference image key.
+type BuildOrder struct {
+	Images    []string `yaml:"images"`
+	Reference string   `yaml:"reference"`
+}
+
+// ImageBuild is the build-date and vcs-ref of a snapshot image.
+type ImageBuild struct {
+	Key    string
+	Image  string
+	Date   time.Time
+	VcsRef string
+}
+
+// DefaultBuildDatePolicy accepts images up to 90 days old and reports rebuilds over 24 hours apart.
+func DefaultBuildDatePolicy() BuildDatePolicy {
+	return BuildDatePolicy{MaxAgeDays: defaultMaxBuildAgeDays, RebuildHours: defaultRebuildHours}
+}
+
+// Validate checks the release date and build orders.
+func (p BuildDatePolicy) Validate() error {
+	if p.MaxAgeDays < 0 || p.RebuildHours < 0 {
+		return errors.New("maxAgeDays and rebuildHours must not be negative")
+	}
+	if p.ReleaseDate != "" {
+		if _, err := time.Parse(releaseDateLayout, p.ReleaseDate); err != nil {
+			return fmt.Errorf("invalid releaseDate %q: %w", p.ReleaseDate, err)
+		}
+	}
+	for index, order := range p.BuiltAfter {
+		if order.Reference == "" || len(order.Images) == 0 {
+			return fmt.Errorf("builtAfter %d: images and reference are required", index)
+		}
+	}
+	return nil
+}
+
+// Release returns the release date, today when not configured.
+func (p BuildDatePolicy) Release() time.Time {
+	if release, err := time.Parse(releaseDateLayout, p.ReleaseDate); err == nil {
+		return release
+	}
+	return time.Now().UTC()
+}
+
+// ParseBuildDate parses a build-date label.
+func ParseBuildDate(value string) (time.Time, error) {
+	for _, layout := range buildDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported build-date %q", value)
+}
+
+// CollectImageBuilds reads build-date and vcs-ref labels of the images, sorted by build date.
+// Images without a valid build-date are returned as problems.
+func CollectImageBuilds(images map[string]string) ([]ImageBuild, []string, error) {
+	var (
+		builds   []ImageBuild
+		problems []string
+		errs     []error
+	)
+	for _, key := range GetMapKeysSorted(images) {
+		labels, err := InspectImageForLabels(images[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to inspect labels for image %s (%s): %w", key, images[key], err))
+			continue
+		}
+		date, err := ParseBuildDate(labels[buildDateLabel])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		builds = append(builds, ImageBuild{Key: key, Image: images[key], Date: date, VcsRef: labels[vcsRefLabel]})
+	}
+	slices.SortFunc(builds, func(a, b ImageBuild) int {
+		return cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.Key, b.Key))
+	})
+	return builds, problems, errors.Join(errs...)
+}
+
+// CheckBuildDates returns images older than MaxAgeDays at the release date, images built before their reference
+// and references missing from the builds.
+func (p BuildDatePolicy) CheckBuildDates(builds []ImageBuild) []string {
+	var problems []string
+	byKey := make(map[string]ImageBuild)
+	for _, build := range builds {
+		byKey[build.Key] = build
+	}
+	if p.MaxAgeDays > 0 {
+		oldest := p.Release().AddDate(0, 0, -p.MaxAgeDays)
+		for _, build := range builds {
+			if build.Date.Before(oldest) {
+				problems = append(problems, fmt.Sprintf("%s: built %s, more than %d days before the release %s",
+					build.Key, build.Date.Format(time.DateOnly), p.MaxAgeDays, p.Release().Format(releaseDateLayout)))
+			}
+		}
+	}
+	for _, order := range p.BuiltAfter {
+		reference, ok := byKey[order.Reference]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: builtAfter reference missing in snapshot or without a valid build-date",
+				order.Reference))
+			continue
+		}
+		for _, build := range builds {
+			if build.Key != order.Reference && matchesAnyPattern(order.Images, build.Key) && build.Date.Before(reference.Date) {
+				problems = append(problems, fmt.Sprintf("%s: built %s, before %s built %s", build.Key,
+					build.Date.Format(time.RFC3339), reference.Key, reference.Date.Format(time.RFC3339)))
+			}
+		}
+	}
+	return problems
+}
+
+// FindRebuilds reports images of the provenance groups with the same vcs-ref built more than RebuildHours apart.
+func (p BuildDatePolicy) FindRebuilds(builds []ImageBuild, groups []ProvenanceGroup) []string {
+	var rebuilds []string
+	for _, group := range groups {
+		byRef := make(map[string][]ImageBuild)
+		for _, build := range builds {
+			if build.VcsRef != "" && matchesAnyPattern(group.Images, build.Key) {
+				byRef[build.VcsRef] = append(byRef[build.VcsRef], build)
+			}
+		}
+		for _, ref := range GetMapKeysSorted(byRef) {
+			sameRef := byRef[ref]
+			first, last := sameRef[0], sameRef[len(sameRef)-1]
+			if last.Date.Sub(first.Date) > time.Duration(p.RebuildHours)*time.Hour {
+				rebuilds = append(rebuilds, fmt.Sprintf("%s: vcs-ref %s built %s (%s) and %s (%s)", group.Name, ref,
+					first.Date.Format(time.RFC3339), first.Key, last.Date.Format(time.RFC3339), last.Key))
+			}
+		}
+	}
+	return rebuilds
+}
+
+// FormatBuildTimeline lists builds ordered by build date.
+func FormatBuildTimeline(builds []ImageBuild) string {
+	var builder strings.Builder
+	table := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+	fmt.Fprintln(table, "BUILD DATE\tIMAGE\tVCS-REF")
+	for _, build := range builds {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", build.Date.Format(time.RFC3339), build.Key, build.VcsRef)
+	}
+	_ = table.Flush()
+	return builder.String()
+}
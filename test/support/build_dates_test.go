@@ -0,0 +1,90 @@
+package support_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/securesign/structural-tests/test/support"
+)
+
+var _ = Describe("Build dates", func() {
+	date := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			panic(err)
+		}
+		return parsed
+	}
+
+	DescribeTable("parses build-date labels",
+		func(value string, expected string) {
+			parsed, err := support.ParseBuildDate(value)
+			if expected == "" {
+				Expect(err).To(MatchError(ContainSubstring("unsupported build-date")))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed).To(Equal(date(expected)))
+			Expect(parsed.Location()).To(Equal(time.UTC))
+		},
+		Entry("RFC 3339", "2024-05-02T10:20:30Z", "2024-05-02T10:20:30Z"),
+		Entry("RFC 3339 with a time zone", "2024-05-02T12:20:30+02:00", "2024-05-02T10:20:30Z"),
+		Entry("without a time zone", "2024-05-02T10:20:30", "2024-05-02T10:20:30Z"),
+		Entry("with a space", "2024-05-02 10:20:30", "2024-05-02T10:20:30Z"),
+		Entry("date only", "2024-05-02", ""),
+		Entry("empty", "", ""),
+	)
+
+	DescribeTable("checks build dates",
+		func(policy support.BuildDatePolicy, builds []support.ImageBuild, expected ...string) {
+			Expect(policy.CheckBuildDates(builds)).To(HaveExactElements(expected))
+		},
+		Entry("recent images", support.BuildDatePolicy{MaxAgeDays: 90, ReleaseDate: "2024-06-01"},
+			[]support.ImageBuild{{Key: "rekor-server-image", Date: date("2024-03-03T00:00:00Z")}}),
+		Entry("image older than maxAgeDays", support.BuildDatePolicy{MaxAgeDays: 90, ReleaseDate: "2024-06-01"},
+			[]support.ImageBuild{{Key: "rekor-server-image", Date: date("2024-03-02T23:59:59Z")}},
+			"rekor-server-image: built 2024-03-02, more than 90 days before the release 2024-06-01"),
+		Entry("no age check", support.BuildDatePolicy{ReleaseDate: "2024-06-01"},
+			[]support.ImageBuild{{Key: "rekor-server-image", Date: date("2020-01-01T00:00:00Z")}}),
+		Entry("image built before its reference",
+			support.BuildDatePolicy{BuiltAfter: []support.BuildOrder{{Images: []string{"*-bundle-image"}, Reference: "operator-image"}}},
+			[]support.ImageBuild{
+				{Key: "operator-bundle-image", Date: date("2024-05-01T00:00:00Z")},
+				{Key: "operator-image", Date: date("2024-05-02T00:00:00Z")},
+				{Key: "other-bundle-image", Date: date("2024-05-03T00:00:00Z")},
+			},
+			"operator-bundle-image: built 2024-05-01T00:00:00Z, before operator-image built 2024-05-02T00:00:00Z"),
+		Entry("reference matching its own pattern",
+			support.BuildDatePolicy{BuiltAfter: []support.BuildOrder{{Images: []string{"operator-*"}, Reference: "operator-image"}}},
+			[]support.ImageBuild{{Key: "operator-image", Date: date("2024-05-02T00:00:00Z")}}),
+		Entry("missing reference",
+			support.BuildDatePolicy{BuiltAfter: []support.BuildOrder{{Images: []string{"*-bundle-image"}, Reference: "operator-image"}}},
+			[]support.ImageBuild{{Key: "operator-bundle-image", Date: date("2024-05-01T00:00:00Z")}},
+			"operator-image: builtAfter reference missing in snapshot or without a valid build-date"),
+	)
+
+	DescribeTable("finds rebuilds of a vcs-ref",
+		func(builds []support.ImageBuild, expected ...string) {
+			groups := []support.ProvenanceGroup{{Name: "rekor", Images: []string{"rekor-*"}}}
+			Expect(support.BuildDatePolicy{RebuildHours: 24}.FindRebuilds(builds, groups)).To(HaveExactElements(expected))
+		},
+		Entry("builds within rebuildHours", []support.ImageBuild{
+			{Key: "rekor-server-image", Date: date("2024-05-01T00:00:00Z"), VcsRef: "abc"},
+			{Key: "rekor-cli-image", Date: date("2024-05-02T00:00:00Z"), VcsRef: "abc"},
+		}),
+		Entry("rebuild", []support.ImageBuild{
+			{Key: "rekor-server-image", Date: date("2024-05-01T00:00:00Z"), VcsRef: "abc"},
+			{Key: "rekor-cli-image", Date: date("2024-05-02T00:00:01Z"), VcsRef: "abc"},
+		}, "rekor: vcs-ref abc built 2024-05-01T00:00:00Z (rekor-server-image) and 2024-05-02T00:00:01Z (rekor-cli-image)"),
+		Entry("different vcs-ref", []support.ImageBuild{
+			{Key: "rekor-server-image", Date: date("2024-05-01T00:00:00Z"), VcsRef: "abc"},
+			{Key: "rekor-cli-image", Date: date("2024-05-09T00:00:00Z"), VcsRef: "def"},
+		}),
+		Entry("images outside the group and without vcs-ref", []support.ImageBuild{
+			{Key: "rekor-server-image", Date: date("2024-05-01T00:00:00Z"), VcsRef: "abc"},
+			{Key: "trillian-db-image", Date: date("2024-05-09T00:00:00Z"), VcsRef: "abc"},
+			{Key: "rekor-cli-image", Date: date("2024-05-09T00:00:00Z")},
+		}),
+	)
+})
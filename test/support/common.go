@@ -34,6 +34,12 @@ func IsVersionAtLeast(testedVersion string) bool {
 	return semver.Compare("v"+actualVersion, "v"+testedVersion) >= 0
 }
 
+// GetVersion returns the tested release version from VERSION or, when unset, from the snapshot path.
+// Returns an empty string when the version cannot be determined.
+func GetVersion() string {
+	return parseVersion()
+}
+
 func parseVersion() string {
 	// get version from environment variable
 	if v := GetEnv(EnvVersion); v != "" {
@@ -53,6 +59,11 @@ func parseVersion() string {
 	return ""
 }
 
+// IsPostGA reports whether the tests verify an already released (GA) version, set by RELEASE_PHASE=post-ga.
+func IsPostGA() bool {
+	return GetEnv(EnvReleasePhase) == ReleasePhasePostGA
+}
+
 func GetEnvAsSecret(key string) string {
 	return getEnv(key, true)
 }
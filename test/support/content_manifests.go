@@ -0,0 +1,284 @@
+package support
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/docker/docker/client"
+)
+
+const (
+	contentManifestsDir = "/root/buildinfo/content_manifests"
+	licensesDir         = "/licenses"
+)
+
+// Paths of os-release in an image filesystem; /etc/os-release is usually a symlink to the first one.
+//
+//nolint:gochecknoglobals // read-only list of paths
+var osReleasePaths = []string{"/usr/lib/os-release", "/etc/os-release"}
+
+// ContentManifestPolicy configures the content manifest and license check (contentManifests section of the suite config).
+type ContentManifestPolicy struct {
+	// Exclude are keys (path.Match patterns) of images not checked.
+	Exclude []string `yaml:"exclude"`
+	// LicensesOnly are keys of images without RPM content, e.g. bundles built from scratch, checked for licenses only.
+	LicensesOnly []string `yaml:"licensesOnly"`
+	// ContentSets maps a RHEL major version to the allowed content sets (path.Match patterns), where {arch} is
+	// replaced by the RPM architecture of the platform, e.g. rhel-9-for-{arch}-baseos*-rpms.
+	ContentSets map[string][]string `yaml:"contentSets"`
+}
+
+// DefaultContentManifestPolicy allows the BaseOS and AppStream content sets of RHEL 8, 9 and 10.
+func DefaultContentManifestPolicy() ContentManifestPolicy {
+	contentSets := make(map[string][]string)
+	for _, major := range []string{"8", "9", "10"} {
+		contentSets[major] = []string{
+			"rhel-" + major + "-for-{arch}-baseos*-rpms",
+			"rhel-" + major + "-for-{arch}-appstream*-rpms",
+		}
+	}
+	return ContentManifestPolicy{ContentSets: contentSets}
+}
+
+// Validate checks that content set patterns are valid.
+func (p ContentManifestPolicy) Validate() error {
+	for major, patterns := range p.ContentSets {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("content set pattern %q of RHEL %s: %w", pattern, major, err)
+			}
+		}
+	}
+	return nil
+}
+
+// AllowedContentSets returns the content set patterns of the RHEL major for the platform (os/arch).
+func (p ContentManifestPolicy) AllowedContentSets(major, platform string) []string {
+	arch := platform
+	if _, after, ok := strings.Cut(platform, "/"); ok {
+		arch = after
+	}
+	if label, ok := ArchitectureLabels()[arch]; ok {
+		arch = label
+	}
+	patterns := make([]string, 0, len(p.ContentSets[major]))
+	for _, pattern := range p.ContentSets[major] {
+		patterns = append(patterns, strings.ReplaceAll(pattern, "{arch}", arch))
+	}
+	return patterns
+}
+
+// ContentManifest is the part of a content manifest (/root/buildinfo/content_manifests/*.json) that is checked.
+type ContentManifest struct {
+	ContentSets []string `json:"content_sets"`
+}
+
+// ContentCheck is the result of checking the content manifests and licenses of one image platform.
+type ContentCheck struct {
+	Key       string
+	Platform  string
+	RHEL      string
+	Manifests []string
+	// ContentSets are the content sets of all manifests, sorted and unique.
+	ContentSets []string
+	// Licenses is the number of non-empty files in /licenses.
+	Licenses int
+	Problems []string
+}
+
+// CheckManifests parses the content manifests and checks their content sets against the policy.
+func (c *ContentCheck) CheckManifests(manifests map[string][]byte, policy ContentManifestPolicy) {
+	if len(manifests) == 0 {
+		c.Problems = append(c.Problems, "no content manifest in "+contentManifestsDir)
+		return
+	}
+	allowed := policy.AllowedContentSets(c.RHEL, c.Platform)
+	if c.RHEL == "" {
+		c.Problems = append(c.Problems, "cannot determine the RHEL major version from os-release")
+	} else if len(allowed) == 0 {
+		c.Problems = append(c.Problems, "no content sets configured for RHEL "+c.RHEL)
+	}
+	for _, name := range GetMapKeysSorted(manifests) {
+		c.Manifests = append(c.Manifests, name)
+		var manifest ContentManifest
+		if err := json.Unmarshal(manifests[name], &manifest); err != nil {
+			c.Problems = append(c.Problems, fmt.Sprintf("%s: invalid JSON: %v", name, err))
+			continue
+		}
+		for _, contentSet := range manifest.ContentSets {
+			c.ContentSets = append(c.ContentSets, contentSet)
+			if len(allowed) > 0 && !matchesAnyPattern(allowed, contentSet) {
+				c.Problems = append(c.Problems, fmt.Sprintf("%s: content set %s not expected for RHEL %s", name, contentSet, c.RHEL))
+			}
+		}
+	}
+	slices.Sort(c.ContentSets)
+	c.ContentSets = slices.Compact(c.ContentSets)
+	if len(c.ContentSets) == 0 && len(c.Manifests) > 0 {
+		c.Problems = append(c.Problems, "content manifests declare no content sets")
+	}
+}
+
+// rhelMajor returns the major version of VERSION_ID of an os-release file.
+func rhelMajor(osRelease []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(osRelease))
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "VERSION_ID="); ok {
+			major, _, _ := strings.Cut(strings.Trim(value, `"'`), ".")
+			return major
+		}
+	}
+	return ""
+}
+
+// readTarFiles calls visit for every regular file of a tar stream.
+func readTarFiles(reader io.Reader, visit func(header *tar.Header, content io.Reader) error) error {
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar header: %w", err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if err := visit(header, tarReader); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// CheckImageContent reads the os-release, content manifests and licenses of the platform (os/arch) variant of an image.
+func (p ContentManifestPolicy) CheckImageContent(ctx context.Context, key, imageDefinition, platform string) (ContentCheck, error) {
+	check := ContentCheck{Key: key, Platform: platform}
+	filesystem, err := OpenImageFilesystemOnPlatform(ctx, imageDefinition, platform)
+	if err != nil {
+		return check, err
+	}
+	defer filesystem.Close(ctx) //nolint:errcheck // best effort cleanup
+
+	licenses, err := filesystem.Copy(ctx, licensesDir)
+	switch {
+	case client.IsErrNotFound(err):
+		check.Problems = append(check.Problems, "no "+licensesDir+" directory")
+	case err != nil:
+		return check, err
+	default:
+		err = readTarFiles(licenses, func(header *tar.Header, _ io.Reader) error {
+			if header.Size > 0 {
+				check.Licenses++
+			}
+			return nil
+		})
+		licenses.Close()
+		if err != nil {
+			return check, fmt.Errorf("%s: %w", licensesDir, err)
+		}
+		if check.Licenses == 0 {
+			check.Problems = append(check.Problems, licensesDir+" contains no non-empty file")
+		}
+	}
+	if matchesAnyPattern(p.LicensesOnly, key) {
+		return check, nil
+	}
+
+	for _, osReleasePath := range osReleasePaths {
+		reader, err := filesystem.Copy(ctx, osReleasePath)
+		if client.IsErrNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return check, err
+		}
+		var osRelease bytes.Buffer
+		err = extractFileFromTar(reader, &osRelease)
+		reader.Close()
+		if err == nil {
+			check.RHEL = rhelMajor(osRelease.Bytes())
+			break
+		}
+	}
+
+	manifests := make(map[string][]byte)
+	reader, err := filesystem.Copy(ctx, contentManifestsDir)
+	if err != nil && !client.IsErrNotFound(err) {
+		return check, err
+	}
+	if err == nil {
+		err = readTarFiles(reader, func(header *tar.Header, content io.Reader) error {
+			if path.Ext(header.Name) != ".json" {
+				return nil
+			}
+			data, err := io.ReadAll(content)
+			if err != nil {
+				return fmt.Errorf("read %s: %w", header.Name, err)
+			}
+			manifests[path.Base(header.Name)] = data
+			return nil
+		})
+		reader.Close()
+		if err != nil {
+			return check, fmt.Errorf("%s: %w", contentManifestsDir, err)
+		}
+	}
+	check.CheckManifests(manifests, p)
+	return check, nil
+}
+
+// CheckSnapshotContent checks every platform of the images not excluded by the policy, sorted by key and platform.
+func CheckSnapshotContent(ctx context.Context, images map[string]string, policy ContentManifestPolicy) ([]ContentCheck, error) {
+	var (
+		checks []ContentCheck
+		errs   []error
+	)
+	for _, key := range GetMapKeysSorted(images) {
+		if matchesAnyPattern(policy.Exclude, key) {
+			continue
+		}
+		platformImages, err := ExpandManifestList(ctx, images[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		for _, platformImage := range platformImages {
+			check, err := policy.CheckImageContent(ctx, key, platformImage.Image, platformImage.Platform)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s (%s): %w", key, platformImage.Platform, err))
+				continue
+			}
+			checks = append(checks, check)
+		}
+	}
+	return checks, errors.Join(errs...)
+}
+
+// FormatContentReport lists the RHEL version, manifests, licenses and content sets per image and platform,
+// followed by the problems found.
+func FormatContentReport(checks []ContentCheck) string {
+	var builder strings.Builder
+	table := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+	fmt.Fprintln(table, "IMAGE\tPLATFORM\tRHEL\tMANIFESTS\tLICENSES\tCONTENT SETS")
+	for _, check := range checks {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%d\t%s\n",
+			check.Key, check.Platform, check.RHEL, len(check.Manifests), check.Licenses, strings.Join(check.ContentSets, ","))
+	}
+	_ = table.Flush()
+	for _, check := range checks {
+		for _, problem := range check.Problems {
+			fmt.Fprintf(&builder, "%s (%s): %s\n", check.Key, check.Platform, problem)
+		}
+	}
+	return builder.String()
+}
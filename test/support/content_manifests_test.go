@@ -0,0 +1,60 @@
+package support_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/securesign/structural-tests/test/support"
+)
+
+var _ = Describe("Content manifests", func() {
+	DescribeTable("reads the RHEL major version of os-release",
+		func(osRelease string, expected string) {
+			Expect(support.RHELMajor([]byte(osRelease))).To(Equal(expected))
+		},
+		Entry("quoted minor version", "NAME=\"Red Hat Enterprise Linux\"\nVERSION_ID=\"9.4\"\nID=\"rhel\"\n", "9"),
+		Entry("single quotes", "VERSION_ID='10.0'\n", "10"),
+		Entry("unquoted major version", "VERSION_ID=8\n", "8"),
+		Entry("no VERSION_ID", "NAME=\"Red Hat Enterprise Linux\"\nVERSION=\"9.4 (Plow)\"\n", ""),
+		Entry("empty", "", ""),
+	)
+
+	DescribeTable("checks content sets",
+		func(rhel, platform string, manifests map[string]string, contentSets []string, expected ...string) {
+			check := support.ContentCheck{Key: "rekor-server-image", Platform: platform, RHEL: rhel}
+			data := make(map[string][]byte)
+			for name, content := range manifests {
+				data[name] = []byte(content)
+			}
+			check.CheckManifests(data, support.DefaultContentManifestPolicy())
+			Expect(check.ContentSets).To(HaveExactElements(contentSets))
+			Expect(check.Problems).To(HaveExactElements(expected))
+		},
+		Entry("allowed content sets of the architecture", "9", "linux/arm64",
+			map[string]string{
+				"a.json": `{"content_sets": ["rhel-9-for-aarch64-baseos-eus-rpms", "rhel-9-for-aarch64-appstream-rpms"]}`,
+				"b.json": `{"content_sets": ["rhel-9-for-aarch64-baseos-eus-rpms"]}`,
+			},
+			[]string{"rhel-9-for-aarch64-appstream-rpms", "rhel-9-for-aarch64-baseos-eus-rpms"}),
+		Entry("content set of another architecture", "9", "linux/amd64",
+			map[string]string{"a.json": `{"content_sets": ["rhel-9-for-aarch64-baseos-rpms"]}`},
+			[]string{"rhel-9-for-aarch64-baseos-rpms"},
+			"a.json: content set rhel-9-for-aarch64-baseos-rpms not expected for RHEL 9"),
+		Entry("content set of another RHEL", "9", "linux/amd64",
+			map[string]string{"a.json": `{"content_sets": ["rhel-8-for-x86_64-baseos-rpms"]}`},
+			[]string{"rhel-8-for-x86_64-baseos-rpms"},
+			"a.json: content set rhel-8-for-x86_64-baseos-rpms not expected for RHEL 9"),
+		Entry("no content manifest", "9", "linux/amd64", map[string]string{}, nil,
+			"no content manifest in /root/buildinfo/content_manifests"),
+		Entry("unknown RHEL version", "", "linux/amd64",
+			map[string]string{"a.json": `{"content_sets": ["rhel-9-for-x86_64-baseos-rpms"]}`},
+			[]string{"rhel-9-for-x86_64-baseos-rpms"},
+			"cannot determine the RHEL major version from os-release"),
+		Entry("RHEL version without content sets", "7", "linux/amd64",
+			map[string]string{"a.json": `{"content_sets": ["rhel-7-server-rpms"]}`},
+			[]string{"rhel-7-server-rpms"},
+			"no content sets configured for RHEL 7"),
+		Entry("invalid and empty manifests", "9", "linux/amd64",
+			map[string]string{"a.json": `{"content_sets": [`, "b.json": `{"content_sets": []}`}, nil,
+			"a.json: invalid JSON: unexpected end of JSON input", "content manifests declare no content sets"),
+	)
+})
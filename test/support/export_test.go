@@ -0,0 +1,13 @@
+package support
+
+// CheckEntrypoint exports checkEntrypoint to the tests.
+var CheckEntrypoint = checkEntrypoint //nolint:gochecknoglobals // test export
+
+// ExpandVersionPattern exports expandVersionPattern to the tests.
+var ExpandVersionPattern = expandVersionPattern //nolint:gochecknoglobals // test export
+
+// VerifyCommit exports verifyCommit to the tests.
+var VerifyCommit = verifyCommit //nolint:gochecknoglobals // test export
+
+// RHELMajor exports rhelMajor to the tests.
+var RHELMajor = rhelMajor //nolint:gochecknoglobals // test export
@@ -15,11 +15,14 @@ import (
 
 // DescribeFBCImageTests verifies file-based catalog images for the given product.
 // Each FBC version (table entry) uses Ordered so a failure skips only remaining specs
-// for that version; other versions still run.
-func DescribeFBCImageTests(product string, defaultsData []byte) bool {
+// for that version; other versions still run. embeddedDefaults are merged with TEST_CONFIG.
+func DescribeFBCImageTests(product string, embeddedDefaults []byte) bool {
 	return Describe("File-based catalog images", func() {
 		defer GinkgoRecover()
 
+		defaultsData, err := support.SuiteDefaults(embeddedDefaults)
+		Expect(err).NotTo(HaveOccurred(), "failed to load the suite config of %s", product)
+
 		cfg, err := GetFBCConfig(product, defaultsData)
 		Expect(err).NotTo(HaveOccurred(), "failed to load FBC config for product %q", product)
 
@@ -86,6 +89,13 @@ func verifyCatalogImage(cfg FBCConfig, key, fbcImage, bundleImage string) {
 		Expect(bundles).ToNot(BeEmpty())
 		Expect(channels).ToNot(BeEmpty())
 		Expect(packages).ToNot(BeEmpty())
+
+		for _, bundle := range bundles {
+			support.RecordUsedImages(support.UsedImagesFBC, bundle.Image)
+			for _, related := range bundle.RelatedImages {
+				support.RecordUsedImages(support.UsedImagesFBC, related.Image)
+			}
+		}
 	})
 
 	It("extract bundle-image from snapshot.json", func() {
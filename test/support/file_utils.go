@@ -2,9 +2,12 @@ package support
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -12,6 +15,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	testroot "github.com/securesign/structural-tests/test"
@@ -173,6 +177,69 @@ func LoadAnsibleCollectionFromImage(ctx context.Context, imageRef, ansibleImages
 	return lookThroughTarFile(gzReader, ansibleImagesFile)
 }
 
+var githubArtifactURLRegexp = regexp.MustCompile(`^https://github\.com/([^/]+)/([^/]+)/actions/runs/\d+/artifacts/(\d+)/?$`)
+
+// GetAnsibleCollectionArchiveFromURL returns the collection archive referenced by the legacy
+// snapshot format (artifact-signer-ansible.collection.url and .sha256). When ANSIBLE_COLLECTION is set,
+// that local copy is used instead of downloading. GitHub Actions artifact web URLs are downloaded
+// through the GitHub API using TEST_GITHUB_TOKEN. The sha256 from the snapshot is the checksum of the
+// artifact as downloaded (the zip, not the collection tarball inside it) and is required.
+func GetAnsibleCollectionArchiveFromURL(url, expectedSHA256 string) ([]byte, error) {
+	if expectedSHA256 == "" {
+		return nil, fmt.Errorf("snapshot has %s but no %s", AnsibleCollectionURLKey, AnsibleCollectionSHA256Key)
+	}
+	location := url
+	if localCopy := GetEnv(EnvAnsibleCollection); localCopy != "" {
+		location = localCopy
+	} else if match := githubArtifactURLRegexp.FindStringSubmatch(url); match != nil {
+		location = fmt.Sprintf("https://api.github.com/repos/%s/%s/actions/artifacts/%s/zip", match[1], match[2], match[3])
+	}
+	content, err := GetFileContent(location)
+	if err != nil {
+		return nil, fmt.Errorf("get ansible collection from %s: %w", location, err)
+	}
+	if actual := SHA256Hex(content); !strings.EqualFold(actual, expectedSHA256) {
+		return nil, fmt.Errorf("ansible collection checksum mismatch: expected %s, got %s", expectedSHA256, actual)
+	}
+	log.Printf("Verified ansible collection checksum %s\n", expectedSHA256)
+	return unwrapCollectionArchive(content)
+}
+
+// unwrapCollectionArchive returns the collection tarball, unwrapping it from a zip (GitHub Actions artifact) if needed.
+func unwrapCollectionArchive(content []byte) ([]byte, error) {
+	if !bytes.HasPrefix(content, []byte("PK\x03\x04")) {
+		return content, nil
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("open ansible collection zip: %w", err)
+	}
+	for _, file := range zipReader.File {
+		base := filepath.Base(file.Name)
+		if !strings.HasPrefix(base, "redhat-artifact_signer") || !strings.HasSuffix(base, ".tar.gz") {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("open %s in zip: %w", file.Name, err)
+		}
+		defer reader.Close()
+		archive, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read %s from zip: %w", file.Name, err)
+		}
+		log.Printf("Found collection archive in zip: %s (%d bytes)\n", file.Name, len(archive))
+		return archive, nil
+	}
+	return nil, errors.New("redhat-artifact_signer*.tar.gz not found in zip")
+}
+
+// SHA256Hex returns the hex encoded sha256 checksum of content.
+func SHA256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
 func lookThroughTarFile(reader io.Reader, filePath string) ([]byte, error) {
 	tarReader := tar.NewReader(reader)
 	for {
@@ -0,0 +1,175 @@
+package support
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+)
+
+const defaultReleaseBranch = "release-{minor}"
+
+var (
+	ErrCommitNotFound    = errors.New("commit not found")
+	ErrCommitNotOnBranch = errors.New("commit not reachable from release branch")
+	ErrBranchNotFound    = errors.New("release branch not found")
+	ErrMirrorNotFound    = errors.New("git mirror not found")
+	ErrInvalidVcsRef     = errors.New("vcs-ref is not a commit hash")
+)
+
+// vcsRefRegexp matches abbreviated and full commit hashes, the only vcs-ref values passed to git.
+var vcsRefRegexp = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// GitMirrorPolicy configures the vcs-ref check against local git clones (gitMirrors section of the suite config).
+type GitMirrorPolicy struct {
+	// ReleaseBranch is the branch template ({version}, {minor}, {major}) commits must be reachable from.
+	ReleaseBranch string `yaml:"releaseBranch"`
+	// Repositories maps vcs-url labels to mirrors with a path or branch different from the defaults.
+	Repositories map[string]GitMirror `yaml:"repositories"`
+	// Exclude are keys (path.Match patterns) of images not checked, e.g. built from a repository without a clone.
+	Exclude []string `yaml:"exclude"`
+}
+
+// GitMirror is a local clone of a repository.
+type GitMirror struct {
+	// Path of the clone relative to GIT_MIRRORS, default the last element of the vcs-url without .git.
+	Path string `yaml:"path"`
+	// ReleaseBranch overrides GitMirrorPolicy.ReleaseBranch.
+	ReleaseBranch string `yaml:"releaseBranch"`
+}
+
+// CommitCheck is the result of the vcs-ref check of one image.
+type CommitCheck struct {
+	Key    string
+	VcsURL string
+	VcsRef string
+	Mirror string
+	Branch string
+	Err    error
+}
+
+// DefaultGitMirrorPolicy expects commits on release-<major>.<minor> branches.
+func DefaultGitMirrorPolicy() GitMirrorPolicy {
+	return GitMirrorPolicy{ReleaseBranch: defaultReleaseBranch}
+}
+
+// MirrorFor returns the clone path (relative to the mirrors directory) and release branch template of a vcs-url.
+func (p GitMirrorPolicy) MirrorFor(vcsURL string) (string, string) {
+	mirror := GitMirror{}
+	for url, candidate := range p.Repositories {
+		if normalizeSourceURL(url) == normalizeSourceURL(vcsURL) {
+			mirror = candidate
+			break
+		}
+	}
+	if mirror.Path == "" {
+		mirror.Path = strings.TrimSuffix(path.Base(strings.TrimSuffix(vcsURL, "/")), ".git")
+	}
+	if mirror.ReleaseBranch == "" {
+		mirror.ReleaseBranch = p.ReleaseBranch
+	}
+	return mirror.Path, mirror.ReleaseBranch
+}
+
+// VerifyMirrorCommits checks that the vcs-ref of every image is a commit of the clone of its vcs-url in mirrorsDir,
+// reachable from the release branch of version. A missing clone fails the check of the image. Excluded images and images
+// without vcs labels are skipped with the reason.
+func VerifyMirrorCommits(ctx context.Context, mirrorsDir string, images map[string]string, policy GitMirrorPolicy,
+	version string) ([]CommitCheck, map[string]string, error) {
+	if version == "" {
+		return nil, nil, errors.New("VERSION is required to find release branches")
+	}
+	var (
+		checks []CommitCheck
+		errs   []error
+	)
+	skipped := make(map[string]string)
+	for _, key := range GetMapKeysSorted(images) {
+		if matchesAnyPattern(policy.Exclude, key) {
+			skipped[key] = "excluded by gitMirrors.exclude"
+			continue
+		}
+		labels, err := InspectImageForLabels(images[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to inspect labels for image %s (%s): %w", key, images[key], err))
+			continue
+		}
+		check := CommitCheck{Key: key, VcsURL: labels[vcsURLLabel], VcsRef: labels[vcsRefLabel]}
+		if check.VcsURL == "" || check.VcsRef == "" {
+			skipped[key] = "no vcs-url or vcs-ref label"
+			continue
+		}
+		mirror, branch := policy.MirrorFor(check.VcsURL)
+		check.Mirror = filepath.Join(mirrorsDir, mirror)
+		check.Branch = ExpandReleaseTags([]string{branch}, version)[0]
+		if _, err := os.Stat(check.Mirror); err != nil {
+			check.Err = fmt.Errorf("%w for %s", ErrMirrorNotFound, check.VcsURL)
+		} else {
+			check.Err = verifyCommit(ctx, check.Mirror, check.VcsRef, check.Branch)
+		}
+		checks = append(checks, check)
+	}
+	return checks, skipped, errors.Join(errs...)
+}
+
+// verifyCommit checks that ref is a commit of the repository reachable from a local or remote-tracking branch.
+// Only commit hashes are passed to git.
+func verifyCommit(ctx context.Context, repository, ref, branch string) error {
+	if !vcsRefRegexp.MatchString(ref) {
+		return fmt.Errorf("%w: %q", ErrInvalidVcsRef, ref)
+	}
+	if _, err := git(ctx, repository, "cat-file", "-e", ref+"^{commit}"); err != nil {
+		return fmt.Errorf("%w: %s", ErrCommitNotFound, ref)
+	}
+	branchRef := ""
+	for _, candidate := range []string{"refs/heads/" + branch, "refs/remotes/origin/" + branch} {
+		if _, err := git(ctx, repository, "rev-parse", "--verify", "--quiet", candidate+"^{commit}"); err == nil {
+			branchRef = candidate
+			break
+		}
+	}
+	if branchRef == "" {
+		return fmt.Errorf("%w: %s", ErrBranchNotFound, branch)
+	}
+	_, err := git(ctx, repository, "merge-base", "--is-ancestor", ref, branchRef)
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return fmt.Errorf("%w: %s is not on %s", ErrCommitNotOnBranch, ref, branch)
+	default:
+		return err
+	}
+}
+
+func git(ctx context.Context, repository string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", repository}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s in %s: %w", strings.Join(args, " "), repository, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// FormatCommitChecks lists the commit checks as an aligned table.
+func FormatCommitChecks(checks []CommitCheck) string {
+	var builder strings.Builder
+	table := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+	fmt.Fprintln(table, "IMAGE\tVCS-REF\tMIRROR\tBRANCH\tRESULT")
+	for _, check := range checks {
+		result := "ok"
+		if check.Err != nil {
+			result = check.Err.Error()
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", check.Key, check.VcsRef, check.Mirror, check.Branch, result)
+	}
+	_ = table.Flush()
+	return builder.String()
+}
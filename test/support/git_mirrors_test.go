@@ -0,0 +1,82 @@
+package support_test
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/securesign/structural-tests/test/support"
+)
+
+var _ = Describe("Git mirrors", func() {
+	DescribeTable("finds the mirror of a vcs-url",
+		func(vcsURL, expectedPath, expectedBranch string) {
+			policy := support.GitMirrorPolicy{
+				ReleaseBranch: "release-{minor}",
+				Repositories: map[string]support.GitMirror{
+					"https://github.com/securesign/sigstore-ocp": {Path: "helm/sigstore-ocp", ReleaseBranch: "release-{version}"},
+				},
+			}
+			mirror, branch := policy.MirrorFor(vcsURL)
+			Expect(mirror).To(Equal(expectedPath))
+			Expect(branch).To(Equal(expectedBranch))
+		},
+		Entry("last element of the url", "https://github.com/securesign/rekor", "rekor", "release-{minor}"),
+		Entry("trailing slash and .git trimmed", "https://github.com/securesign/rekor.git/", "rekor", "release-{minor}"),
+		Entry("case kept", "https://github.com/securesign/Rekor-Search-UI", "Rekor-Search-UI", "release-{minor}"),
+		Entry("configured repository", "https://GitHub.com/securesign/sigstore-ocp.git", "helm/sigstore-ocp", "release-{version}"),
+	)
+
+	Describe("verifies commits", Ordered, func() {
+		var repository, releaseCommit, featureCommit string
+
+		git := func(args ...string) string {
+			cmd := exec.Command("git", append([]string{"-C", repository, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+			out, err := cmd.CombinedOutput()
+			Expect(err).NotTo(HaveOccurred(), string(out))
+			return strings.TrimSpace(string(out))
+		}
+
+		BeforeAll(func() {
+			if _, err := exec.LookPath("git"); err != nil {
+				Skip("git is not installed")
+			}
+			repository = GinkgoT().TempDir()
+			git("init", "--quiet", "--initial-branch", "main")
+			git("commit", "--quiet", "--allow-empty", "-m", "release")
+			releaseCommit = git("rev-parse", "HEAD")
+			git("branch", "release-1.2")
+			git("commit", "--quiet", "--allow-empty", "-m", "feature")
+			featureCommit = git("rev-parse", "HEAD")
+		})
+
+		It("accepts a commit on the release branch", func() {
+			Expect(support.VerifyCommit(context.Background(), repository, releaseCommit, "release-1.2")).To(Succeed())
+			Expect(support.VerifyCommit(context.Background(), repository, releaseCommit[:7], "release-1.2")).To(Succeed())
+		})
+
+		It("reports a commit not on the release branch", func() {
+			Expect(support.VerifyCommit(context.Background(), repository, featureCommit, "release-1.2")).
+				To(MatchError(support.ErrCommitNotOnBranch))
+		})
+
+		It("reports a missing release branch", func() {
+			Expect(support.VerifyCommit(context.Background(), repository, releaseCommit, "release-1.3")).
+				To(MatchError(support.ErrBranchNotFound))
+		})
+
+		It("reports an unknown commit", func() {
+			Expect(support.VerifyCommit(context.Background(), repository, strings.Repeat("0", 40), "release-1.2")).
+				To(MatchError(support.ErrCommitNotFound))
+		})
+
+		It("does not pass an invalid vcs-ref to git", func() {
+			for _, ref := range []string{"--output=/tmp/x", "HEAD", "release-1.2", releaseCommit[:6], strings.ToUpper(releaseCommit)} {
+				Expect(support.VerifyCommit(context.Background(), repository, ref, "release-1.2")).
+					To(MatchError(support.ErrInvalidVcsRef), ref)
+			}
+		})
+	})
+})
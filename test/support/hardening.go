@@ -0,0 +1,269 @@
+package support
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+const (
+	defaultImagePath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+	// maxSymlinkDepth bounds the symlinks followed to an entrypoint executable, so that loops end.
+	maxSymlinkDepth = 16
+)
+
+var errSymlinkDepth = errors.New("too many levels of symbolic links")
+
+//nolint:gochecknoglobals // read-only patterns
+var (
+	// secretNameRegexp matches names of variables and build arguments holding secrets.
+	secretNameRegexp = regexp.MustCompile(`(?i)(passw(or)?d|secret|token|api_?key|private_?key|credential)`)
+	// secretValueRegexps match well-known secret formats anywhere in the image config.
+	secretValueRegexps = []*regexp.Regexp{
+		regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+		regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36}\b`),
+		regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20}\b`),
+		regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+		regexp.MustCompile(`\bxox[abpr]-[A-Za-z0-9-]{10,}\b`),
+	}
+	// assignmentRegexp matches NAME=value assignments in image history commands.
+	assignmentRegexp = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)=("[^"]*"|'[^']*'|\S+)`)
+)
+
+// HardeningPolicy configures the image config hardening check (hardening section of the suite config).
+type HardeningPolicy struct {
+	// Images are keys (path.Match patterns) of the checked service images.
+	Images []string `yaml:"images"`
+	// Exclude are keys of images not checked, e.g. bundles and catalogs.
+	Exclude []string `yaml:"exclude"`
+	// AllowRoot are keys of images allowed to run as root.
+	AllowRoot []string `yaml:"allowRoot"`
+	// Ports maps image keys (path.Match patterns) to their allowed exposed ports (e.g. 8080/tcp), by first match
+	// in sorted order. Images without an entry must not expose any port.
+	Ports map[string][]string `yaml:"ports"`
+	// AllowEnv are names of variables matching the secret name pattern without holding a secret, e.g. TOKEN_PATH.
+	AllowEnv []string `yaml:"allowEnv"`
+}
+
+// DefaultHardeningPolicy checks every image.
+func DefaultHardeningPolicy() HardeningPolicy {
+	return HardeningPolicy{Images: []string{"*"}}
+}
+
+// Applies reports whether the image key is checked.
+func (p HardeningPolicy) Applies(key string) bool {
+	return matchesAnyPattern(p.Images, key) && !matchesAnyPattern(p.Exclude, key)
+}
+
+// AllowedPorts returns the ports the image key may expose.
+func (p HardeningPolicy) AllowedPorts(key string) []string {
+	if ports, ok := p.Ports[key]; ok {
+		return ports
+	}
+	for _, pattern := range GetMapKeysSorted(p.Ports) {
+		if matched, err := path.Match(pattern, key); err == nil && matched {
+			return p.Ports[pattern]
+		}
+	}
+	return nil
+}
+
+// CheckImageConfig returns hardening problems of an image config and history that need no filesystem access.
+func (p HardeningPolicy) CheckImageConfig(key string, config *container.Config, history []string) []string {
+	if config == nil {
+		return []string{"image has no config"}
+	}
+	var problems []string
+	if isRootUser(config.User) && !matchesAnyPattern(p.AllowRoot, key) {
+		problems = append(problems, fmt.Sprintf("runs as root (user %q)", config.User))
+	}
+	allowed := p.AllowedPorts(key)
+	for port := range config.ExposedPorts {
+		if !slices.Contains(allowed, string(port)) {
+			problems = append(problems, fmt.Sprintf("exposes port %s, allowed: %v", port, allowed))
+		}
+	}
+	for _, env := range config.Env {
+		name, value, _ := strings.Cut(env, "=")
+		if problem := p.secretProblem(name, value); problem != "" {
+			problems = append(problems, "env "+problem)
+		}
+	}
+	for index, command := range history {
+		for _, match := range assignmentRegexp.FindAllStringSubmatch(command, -1) {
+			if problem := p.secretProblem(match[1], strings.Trim(match[2], `"'`)); problem != "" {
+				problems = append(problems, fmt.Sprintf("history layer %d: %s", index, problem))
+			}
+		}
+		if problem := secretValueProblem(command); problem != "" {
+			problems = append(problems, fmt.Sprintf("history layer %d: %s", index, problem))
+		}
+	}
+	slices.Sort(problems)
+	return slices.Compact(problems)
+}
+
+// secretProblem describes a variable that looks like a secret, without revealing its value.
+func (p HardeningPolicy) secretProblem(name, value string) string {
+	if value == "" || slices.Contains(p.AllowEnv, name) {
+		return ""
+	}
+	if problem := secretValueProblem(value); problem != "" {
+		return name + ": " + problem
+	}
+	if secretNameRegexp.MatchString(name) && !strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "$") {
+		return name + ": looks like a secret"
+	}
+	return ""
+}
+
+func secretValueProblem(value string) string {
+	for _, pattern := range secretValueRegexps {
+		if pattern.MatchString(value) {
+			return "contains a value matching " + pattern.String()
+		}
+	}
+	return ""
+}
+
+// isRootUser reports whether a config User (name or uid, optionally with :group) is root. Empty means root.
+func isRootUser(user string) bool {
+	name, _, _ := strings.Cut(user, ":")
+	return name == "" || name == "root" || name == "0"
+}
+
+// CheckImageHardening inspects an image and checks its config, history and that its entrypoint exists.
+func (p HardeningPolicy) CheckImageHardening(ctx context.Context, key, imageDefinition string) ([]string, error) {
+	if err := PullImageIfNotPresentLocally(ctx, imageDefinition); err != nil {
+		return nil, err
+	}
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("docker client: %w", err)
+	}
+	defer cli.Close()
+	inspectData, _, err := cli.ImageInspectWithRaw(ctx, imageDefinition)
+	if err != nil {
+		return nil, fmt.Errorf("cannot inspect image %s: %w", imageDefinition, err)
+	}
+	historyItems, err := cli.ImageHistory(ctx, imageDefinition)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read history of image %s: %w", imageDefinition, err)
+	}
+	history := make([]string, 0, len(historyItems))
+	for _, item := range historyItems {
+		history = append(history, item.CreatedBy+" "+item.Comment)
+	}
+	problems := p.CheckImageConfig(key, inspectData.Config, history)
+	if inspectData.Config == nil {
+		return problems, nil
+	}
+
+	filesystem, err := OpenImageFilesystem(ctx, imageDefinition)
+	if err != nil {
+		return nil, err
+	}
+	defer filesystem.Close(ctx) //nolint:errcheck // best effort cleanup
+	stat := func(name string) (container.PathStat, error) {
+		return filesystem.Stat(ctx, name)
+	}
+	if problem := checkEntrypoint(stat, inspectData.Config); problem != "" {
+		problems = append(problems, problem)
+	}
+	return problems, nil
+}
+
+// statFunc returns the stat of a path of an image filesystem, not following a final symlink.
+type statFunc func(name string) (container.PathStat, error)
+
+// checkEntrypoint checks that the executable of the entrypoint, or of the cmd without entrypoint, exists. Symlinks are
+// followed, a dangling one is reported.
+func checkEntrypoint(stat statFunc, config *container.Config) string {
+	command := config.Entrypoint
+	if len(command) == 0 {
+		command = config.Cmd
+	}
+	if len(command) == 0 {
+		return "no entrypoint or cmd"
+	}
+	executable := command[0]
+	candidates := []string{executable}
+	if !path.IsAbs(executable) {
+		candidates = nil
+		for _, dir := range strings.Split(envPath(config.Env), ":") {
+			candidates = append(candidates, path.Join(dir, executable))
+		}
+	}
+	for _, candidate := range candidates {
+		info, err := stat(candidate)
+		if err != nil {
+			continue
+		}
+		target, info, err := resolveSymlinks(stat, candidate, info)
+		if err != nil {
+			return fmt.Sprintf("entrypoint %s is a dangling symlink: %v", candidate, err)
+		}
+		if info.Mode.IsDir() || info.Mode.Perm()&0o111 == 0 {
+			return fmt.Sprintf("entrypoint %s is not executable (%s %s)", candidate, target, info.Mode)
+		}
+		return ""
+	}
+	return fmt.Sprintf("entrypoint %s not found in the image", executable)
+}
+
+// resolveSymlinks follows the symlink name with stat info to its final target. Relative link targets are resolved
+// against the directory of the link.
+func resolveSymlinks(stat statFunc, name string, info container.PathStat) (string, container.PathStat, error) {
+	for range maxSymlinkDepth {
+		if info.Mode&os.ModeSymlink == 0 {
+			return name, info, nil
+		}
+		target := info.LinkTarget
+		if !path.IsAbs(target) {
+			target = path.Join(path.Dir(name), target)
+		}
+		var err error
+		if info, err = stat(target); err != nil {
+			return target, container.PathStat{}, err
+		}
+		name = target
+	}
+	return name, container.PathStat{}, fmt.Errorf("%w at %s", errSymlinkDepth, name)
+}
+
+func envPath(env []string) string {
+	for _, variable := range env {
+		if value, ok := strings.CutPrefix(variable, "PATH="); ok {
+			return value
+		}
+	}
+	return defaultImagePath
+}
+
+// CheckSnapshotHardening checks every snapshot image the policy applies to. Returns problems by image key.
+func CheckSnapshotHardening(ctx context.Context, images map[string]string, policy HardeningPolicy) (map[string][]string, error) {
+	result := make(map[string][]string)
+	var errs []error
+	for _, key := range GetMapKeysSorted(images) {
+		if !policy.Applies(key) {
+			continue
+		}
+		problems, err := policy.CheckImageHardening(ctx, key, images[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		if len(problems) > 0 {
+			result[key] = problems
+		}
+	}
+	return result, errors.Join(errs...)
+}
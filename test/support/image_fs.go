@@ -0,0 +1,68 @@
+package support
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// ImageFilesystem reads the filesystem of an image through a created, never started container.
+type ImageFilesystem struct {
+	cli         *client.Client
+	containerID string
+}
+
+// OpenImageFilesystem pulls the image if needed and creates a container of it. The caller must Close it.
+func OpenImageFilesystem(ctx context.Context, imageDefinition string) (*ImageFilesystem, error) {
+	return OpenImageFilesystemOnPlatform(ctx, imageDefinition, DefaultPlatform)
+}
+
+// OpenImageFilesystemOnPlatform is OpenImageFilesystem for the platform (os/arch) variant of an image.
+func OpenImageFilesystemOnPlatform(ctx context.Context, imageDefinition, platform string) (*ImageFilesystem, error) {
+	if err := PullImageForPlatform(ctx, imageDefinition, platform); err != nil {
+		return nil, err
+	}
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("docker client: %w", err)
+	}
+	resp, err := cli.ContainerCreate(ctx, &container.Config{
+		Image: imageDefinition,
+		Cmd:   []string{"echo", "dummy"},
+	}, nil, nil, nil, "")
+	if err != nil {
+		cli.Close()
+		return nil, fmt.Errorf("create container of %s: %w", imageDefinition, err)
+	}
+	return &ImageFilesystem{cli: cli, containerID: resp.ID}, nil
+}
+
+// Stat returns information about a path, following no symlinks.
+func (f *ImageFilesystem) Stat(ctx context.Context, path string) (container.PathStat, error) {
+	stat, err := f.cli.ContainerStatPath(ctx, f.containerID, path)
+	if err != nil {
+		return container.PathStat{}, fmt.Errorf("stat %s: %w", path, err)
+	}
+	return stat, nil
+}
+
+// Copy returns a tar stream of a file or directory. The caller must close it.
+func (f *ImageFilesystem) Copy(ctx context.Context, path string) (io.ReadCloser, error) {
+	reader, _, err := f.cli.CopyFromContainer(ctx, f.containerID, path)
+	if err != nil {
+		return nil, fmt.Errorf("copy %s from container: %w", path, err)
+	}
+	return reader, nil
+}
+
+// Close removes the container.
+func (f *ImageFilesystem) Close(ctx context.Context) error {
+	defer f.cli.Close()
+	if err := f.cli.ContainerRemove(ctx, f.containerID, container.RemoveOptions{Force: true}); err != nil {
+		return fmt.Errorf("remove container: %w", err)
+	}
+	return nil
+}
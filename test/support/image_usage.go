@@ -0,0 +1,115 @@
+package support
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Sources of used images, see RecordUsedImages.
+const (
+	UsedImagesOperator = "operator"
+	UsedImagesAnsible  = "ansible"
+	UsedImagesFBC      = "fbc"
+	UsedImagesSnapshot = "snapshot"
+)
+
+// OrphanPolicy configures the orphan repository report (repositories.orphans section of the suite config).
+type OrphanPolicy struct {
+	// Fail turns orphan repositories into a suite failure, otherwise they are only reported.
+	Fail bool `yaml:"fail"`
+	// Ignore lists repositories (path.Match patterns) owned by other products or shipped in other ways.
+	Ignore []string `yaml:"ignore"`
+	// SnapshotRepositories maps snapshot image keys to the registry.redhat.io repository they are released to.
+	SnapshotRepositories map[string]string `yaml:"snapshotRepositories"`
+}
+
+var (
+	usedImages      = map[string][]string{} //nolint:gochecknoglobals // collected across specs of one suite
+	usedImagesMutex sync.Mutex
+)
+
+// RecordUsedImages remembers images discovered by a suite (operator, ansible, fbc, ...) for the orphan repository report.
+func RecordUsedImages(source string, images ...string) {
+	usedImagesMutex.Lock()
+	defer usedImagesMutex.Unlock()
+	for _, image := range images {
+		if image != "" && !slices.Contains(usedImages[image], source) {
+			usedImages[image] = append(usedImages[image], source)
+		}
+	}
+}
+
+// HasUsedImages reports whether any image was recorded from source, i.e. whether that suite ran.
+func HasUsedImages(source string) bool {
+	usedImagesMutex.Lock()
+	defer usedImagesMutex.Unlock()
+	for _, sources := range usedImages {
+		if slices.Contains(sources, source) {
+			return true
+		}
+	}
+	return false
+}
+
+// UsedImages returns all recorded images.
+func UsedImages() []string {
+	usedImagesMutex.Lock()
+	defer usedImagesMutex.Unlock()
+	return GetMapKeysSorted(usedImages)
+}
+
+// RecordSnapshotImages records snapshot images. Images of keys listed in repositories are recorded as their
+// registry.redhat.io repository, because snapshot images point to the build registry.
+func RecordSnapshotImages(snapshotData SnapshotData, repositories map[string]string) {
+	for key, image := range snapshotData.Images {
+		if repository, ok := repositories[key]; ok {
+			image = "registry.redhat.io/" + repository + ":latest"
+		}
+		RecordUsedImages(UsedImagesSnapshot, image)
+	}
+}
+
+// OrphanRepositories returns repositories of the list not referenced by any of images and not ignored by the policy.
+func (r *RepositoryList) OrphanRepositories(images []string, policy OrphanPolicy) []Repository {
+	used := make(map[string]bool)
+	for _, image := range images {
+		if repository := r.FindByImage(image); repository != nil {
+			used[repository.Name] = true
+		}
+	}
+	var orphans []Repository
+	for _, repository := range r.Data {
+		if used[repository.Name] || isIgnoredRepository(repository.Name, policy.Ignore) {
+			continue
+		}
+		orphans = append(orphans, repository)
+	}
+	return orphans
+}
+
+func isIgnoredRepository(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		matched, err := path.Match(pattern, name)
+		if err != nil {
+			log.Printf("invalid orphans.ignore pattern %q: %v\n", pattern, err)
+			continue
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatOrphanReport lists orphan repositories, one per line.
+func FormatOrphanReport(orphans []Repository) string {
+	var builder strings.Builder
+	for _, repository := range orphans {
+		fmt.Fprintf(&builder, "  %s (published: %t)\n", repository.Name, repository.Published)
+	}
+	return builder.String()
+}
@@ -12,11 +12,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	"github.com/securesign/structural-tests/test/support/pyxis"
 )
 
 type ImageData struct {
@@ -24,7 +26,15 @@ type ImageData struct {
 	Labels map[string]string
 }
 
+// DefaultPlatform is the platform pulled when no platform is requested.
+const DefaultPlatform = "linux/amd64"
+
 func PullImageIfNotPresentLocally(ctx context.Context, imageDefinition string) error {
+	return PullImageForPlatform(ctx, imageDefinition, DefaultPlatform)
+}
+
+// PullImageForPlatform pulls the platform (os/arch) variant of an image unless it is present locally.
+func PullImageForPlatform(ctx context.Context, imageDefinition, platform string) error {
 	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
 		return fmt.Errorf("could not create docker client: %w", err)
@@ -38,9 +48,9 @@ func PullImageIfNotPresentLocally(ctx context.Context, imageDefinition string) e
 	}
 
 	if client.IsErrNotFound(err) {
-		log.Printf("Image '%s' not found locally, pulling...\n", imageDefinition)
+		log.Printf("Image '%s' (%s) not found locally, pulling...\n", imageDefinition, platform)
 		pullResp, pullErr := cli.ImagePull(ctx, imageDefinition, image.PullOptions{
-			Platform: "linux/amd64",
+			Platform: platform,
 		})
 		if pullErr != nil {
 			return fmt.Errorf("failed to pull image: %w", pullErr)
@@ -113,8 +123,13 @@ func RunImage(imageDefinition string, entrypoint, commands []string) (string, er
 }
 
 func InspectImageForLabels(imageDefinition string) (map[string]string, error) {
+	return InspectImageForLabelsOnPlatform(imageDefinition, DefaultPlatform)
+}
+
+// InspectImageForLabelsOnPlatform returns the labels of the platform (os/arch) variant of an image.
+func InspectImageForLabelsOnPlatform(imageDefinition, platform string) (map[string]string, error) {
 	ctx := context.TODO()
-	err := PullImageIfNotPresentLocally(ctx, imageDefinition)
+	err := PullImageForPlatform(ctx, imageDefinition, platform)
 	if err != nil {
 		return nil, err
 	}
@@ -307,7 +322,29 @@ func ResolveManifestListForPlatform(ctx context.Context, imageRef, platform stri
 	}
 	wantOS, wantArch := parts[0], parts[1]
 
-	// Try podman first, then docker
+	list, err := inspectManifestList(ctx, imageRef)
+	if err != nil {
+		return imageRef, err
+	}
+	if len(list.Manifests) == 0 {
+		return imageRef, errors.New("manifest list has no manifests")
+	}
+
+	for _, entry := range list.Manifests {
+		if entry.Platform.OS == wantOS && entry.Platform.Architecture == wantArch {
+			repo := imageRef
+			if at := strings.Index(imageRef, "@"); at != -1 {
+				repo = imageRef[:at]
+			}
+			return repo + "@" + entry.Digest, nil
+		}
+	}
+	return imageRef, fmt.Errorf("no manifest for platform %s", platform)
+}
+
+// inspectManifestList runs manifest inspect on imageRef, trying podman first, then docker.
+// A single-arch image results in a list without manifests.
+func inspectManifestList(ctx context.Context, imageRef string) (manifestListOutput, error) {
 	var out []byte
 	var err error
 	for _, cmdName := range []string{"podman", "docker"} {
@@ -319,25 +356,126 @@ func ResolveManifestListForPlatform(ctx context.Context, imageRef, platform stri
 		break
 	}
 	if err != nil {
-		return imageRef, fmt.Errorf("manifest inspect failed (tried podman and docker): %w", err)
+		return manifestListOutput{}, fmt.Errorf("manifest inspect failed (tried podman and docker): %w", err)
 	}
 
 	var list manifestListOutput
 	if err := json.Unmarshal(out, &list); err != nil {
-		return imageRef, fmt.Errorf("parse manifest list: %w", err)
-	}
-	if len(list.Manifests) == 0 {
-		return imageRef, errors.New("manifest list has no manifests")
+		return manifestListOutput{}, fmt.Errorf("parse manifest list: %w", err)
 	}
+	return list, nil
+}
 
+// GetManifestListPlatforms returns the os/arch platforms of a manifest list, without attestation
+// entries (unknown/unknown). Returns an empty list for a single-arch image.
+func GetManifestListPlatforms(ctx context.Context, imageRef string) ([]string, error) {
+	list, err := inspectManifestList(ctx, imageRef)
+	if err != nil {
+		return nil, err
+	}
+	var platforms []string
 	for _, entry := range list.Manifests {
-		if entry.Platform.OS == wantOS && entry.Platform.Architecture == wantArch {
-			repo := imageRef
-			if at := strings.Index(imageRef, "@"); at != -1 {
-				repo = imageRef[:at]
+		if entry.Platform.OS == "" || entry.Platform.OS == "unknown" {
+			continue
+		}
+		platform := entry.Platform.OS + "/" + entry.Platform.Architecture
+		if !slices.Contains(platforms, platform) {
+			platforms = append(platforms, platform)
+		}
+	}
+	return platforms, nil
+}
+
+// GetExpectedGradeArchitectures returns the architectures each image ("registry/repository") is expected to have
+// grades for: the platforms of its manifest list, or fallback platforms (grades.platforms) when the manifest list
+// cannot be inspected. Single-arch images have no expectation.
+func GetExpectedGradeArchitectures(ctx context.Context, images map[string]string, fallback []string) map[string][]string {
+	expected := make(map[string][]string)
+	for _, imageRef := range images {
+		repo := pyxis.RepositoryKey(imageRef)
+		platforms, err := GetManifestListPlatforms(ctx, imageRef)
+		if err != nil {
+			log.Printf("Using configured platforms %v for %s: %v\n", fallback, imageRef, err)
+			platforms = fallback
+		}
+		for _, platform := range platforms {
+			arch := pyxis.PlatformArchitecture(platform)
+			if !slices.Contains(expected[repo], arch) {
+				expected[repo] = append(expected[repo], arch)
 			}
-			return repo + "@" + entry.Digest, nil
 		}
 	}
-	return imageRef, fmt.Errorf("no manifest for platform %s", platform)
+	return expected
+}
+
+// PlatformImage is the image of one platform of a manifest list.
+type PlatformImage struct {
+	Platform string
+	Image    string
+}
+
+// ExpandManifestList returns the platform images (repo@digest) of a manifest list. A single-arch image is returned
+// with the platform of its config. Returns an error when the manifest cannot be inspected, so that callers do not
+// silently check one platform only.
+func ExpandManifestList(ctx context.Context, imageRef string) ([]PlatformImage, error) {
+	list, err := inspectManifestList(ctx, imageRef)
+	if err != nil {
+		return nil, fmt.Errorf("cannot inspect manifest of %s: %w", imageRef, err)
+	}
+	repo := imageRef
+	if at := strings.Index(imageRef, "@"); at != -1 {
+		repo = imageRef[:at]
+	}
+	var images []PlatformImage
+	for _, entry := range list.Manifests {
+		if entry.Platform.OS == "" || entry.Platform.OS == "unknown" {
+			continue
+		}
+		images = append(images, PlatformImage{
+			Platform: entry.Platform.OS + "/" + entry.Platform.Architecture,
+			Image:    repo + "@" + entry.Digest,
+		})
+	}
+	if len(images) == 0 {
+		platform, err := singleManifestPlatform(ctx, imageRef)
+		if err != nil {
+			return nil, err
+		}
+		return []PlatformImage{{Platform: platform, Image: imageRef}}, nil
+	}
+	return images, nil
+}
+
+// singleManifestPlatform returns the os/arch of the config of a single-arch image.
+func singleManifestPlatform(ctx context.Context, imageRef string) (string, error) {
+	if err := PullImageForPlatform(ctx, imageRef, ""); err != nil {
+		return "", err
+	}
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return "", fmt.Errorf("error while initializing docker client: %w", err)
+	}
+	defer cli.Close()
+	inspectData, _, err := cli.ImageInspectWithRaw(ctx, imageRef)
+	if err != nil {
+		return "", fmt.Errorf("cannot inspect image %s: %w", imageRef, err)
+	}
+	return inspectData.Os + "/" + inspectData.Architecture, nil
+}
+
+// ValidateImageLabels returns label problems of labels against required, where an empty required value
+// means the label must exist with any non-empty value. Problems are sorted by label name.
+func ValidateImageLabels(labels, required map[string]string) []string {
+	var problems []string
+	for _, labelName := range GetMapKeysSorted(required) {
+		expectedValue := required[labelName]
+		value, exists := labels[labelName]
+		switch {
+		case !exists || value == "":
+			problems = append(problems, labelName+": missing")
+		case expectedValue != "" && value != expectedValue:
+			problems = append(problems, fmt.Sprintf("%s: %s, expected: %s", labelName, value, expectedValue))
+		}
+	}
+	return problems
 }
@@ -0,0 +1,212 @@
+package support
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// LabelArchitecturePlaceholder in label values and patterns is replaced by the architecture label of the platform.
+const LabelArchitecturePlaceholder = "{architecture}"
+
+// LabelRule lists label requirements of an image.
+type LabelRule struct {
+	// Required labels must exist with any non-empty value.
+	Required []string `yaml:"required"`
+	// Values are labels that must exist with exactly this value.
+	Values map[string]string `yaml:"values"`
+	// Patterns are labels that must exist with a value matching the regular expression.
+	Patterns map[string]string `yaml:"patterns"`
+	// Forbidden labels must not exist.
+	Forbidden []string `yaml:"forbidden"`
+}
+
+// LabelClass is a class of images (service, operator, bundle, FBC, CLI, ...) with its own label rule.
+type LabelClass struct {
+	Name string `yaml:"name"`
+	// Images are image keys (path.Match patterns) of the class.
+	Images    []string `yaml:"images"`
+	LabelRule `yaml:",inline"`
+}
+
+// LabelPolicy configures image label checks (labels section of the suite config). Every image must satisfy
+// the Common rule and the rule of the first class matching its key.
+type LabelPolicy struct {
+	Common  LabelRule    `yaml:"common"`
+	Classes []LabelClass `yaml:"classes"`
+}
+
+// DefaultLabelPolicy requires RequiredImageLabels from every image.
+func DefaultLabelPolicy() LabelPolicy {
+	values := RequiredImageLabels()
+	values["architecture"] = LabelArchitecturePlaceholder
+	var rule LabelRule
+	for label, value := range values {
+		if value == "" {
+			rule.Required = append(rule.Required, label)
+			continue
+		}
+		if rule.Values == nil {
+			rule.Values = make(map[string]string)
+		}
+		rule.Values[label] = value
+	}
+	slices.Sort(rule.Required)
+	return LabelPolicy{Common: rule}
+}
+
+// Validate checks that classes are named and match some images, and that patterns are valid regular expressions.
+func (p LabelPolicy) Validate() error {
+	rules := map[string]LabelRule{"common": p.Common}
+	for index, class := range p.Classes {
+		if class.Name == "" {
+			return fmt.Errorf("class %d: missing name", index)
+		}
+		if len(class.Images) == 0 {
+			return fmt.Errorf("class %s: missing images", class.Name)
+		}
+		for _, pattern := range class.Images {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("class %s: invalid image pattern %q: %w", class.Name, pattern, err)
+			}
+		}
+		rules[class.Name] = class.LabelRule
+	}
+	for name, rule := range rules {
+		for label, pattern := range rule.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("%s: invalid pattern of label %s: %w", name, label, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ClassOf returns the first class matching the image key, nil if none does.
+func (p LabelPolicy) ClassOf(key string) *LabelClass {
+	for index, class := range p.Classes {
+		for _, pattern := range class.Images {
+			if matched, err := path.Match(pattern, key); err == nil && matched {
+				return &p.Classes[index]
+			}
+		}
+	}
+	return nil
+}
+
+// RuleFor returns the Common rule merged with the rule of the image class. Class values override common values.
+func (p LabelPolicy) RuleFor(key string) LabelRule {
+	rule := LabelRule{
+		Required:  slices.Clone(p.Common.Required),
+		Values:    make(map[string]string),
+		Patterns:  make(map[string]string),
+		Forbidden: slices.Clone(p.Common.Forbidden),
+	}
+	classRule := LabelRule{}
+	if class := p.ClassOf(key); class != nil {
+		classRule = class.LabelRule
+	}
+	rule.Required = append(rule.Required, classRule.Required...)
+	rule.Forbidden = append(rule.Forbidden, classRule.Forbidden...)
+	for _, values := range []map[string]string{p.Common.Values, classRule.Values} {
+		for label, value := range values {
+			rule.Values[label] = value
+		}
+	}
+	for _, patterns := range []map[string]string{p.Common.Patterns, classRule.Patterns} {
+		for label, pattern := range patterns {
+			rule.Patterns[label] = pattern
+		}
+	}
+	return rule
+}
+
+// CheckLabels returns label problems of an image key on a platform (os/arch), sorted by label name.
+func (p LabelPolicy) CheckLabels(key, platform string, labels map[string]string) []string {
+	rule := p.RuleFor(key)
+	architecture := RequiredImageLabelsForPlatform(platform)["architecture"]
+	expand := func(value string) string {
+		return strings.ReplaceAll(value, LabelArchitecturePlaceholder, architecture)
+	}
+
+	required := make(map[string]string)
+	for _, label := range rule.Required {
+		required[label] = ""
+	}
+	for label, value := range rule.Values {
+		required[label] = expand(value)
+	}
+	problems := ValidateImageLabels(labels, required)
+
+	for _, label := range GetMapKeysSorted(rule.Patterns) {
+		value := labels[label]
+		pattern, err := regexp.Compile(expand(rule.Patterns[label]))
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("%s: invalid pattern: %v", label, err))
+		case value == "":
+			problems = append(problems, label+": missing")
+		case !pattern.MatchString(value):
+			problems = append(problems, fmt.Sprintf("%s: %s, expected to match: %s", label, value, pattern))
+		}
+	}
+	for _, label := range rule.Forbidden {
+		if value, exists := labels[label]; exists {
+			problems = append(problems, fmt.Sprintf("%s: %s, forbidden", label, value))
+		}
+	}
+	slices.Sort(problems)
+	return slices.Compact(problems)
+}
+
+// CheckSnapshotLabels checks the labels of every platform of the snapshot images against the policy.
+// Returns label problems by image key and platform.
+func CheckSnapshotLabels(ctx context.Context, images map[string]string, policy LabelPolicy) (map[string]map[string][]string, error) {
+	result := make(map[string]map[string][]string)
+	var errs []error
+	for _, key := range GetMapKeysSorted(images) {
+		platformImages, err := ExpandManifestList(ctx, images[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		for _, platformImage := range platformImages {
+			labels, err := InspectImageForLabelsOnPlatform(platformImage.Image, platformImage.Platform)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to inspect labels for image %s (%s, %s): %w",
+					key, platformImage.Image, platformImage.Platform, err))
+				continue
+			}
+			if problems := policy.CheckLabels(key, platformImage.Platform, labels); len(problems) > 0 {
+				if result[key] == nil {
+					result[key] = make(map[string][]string)
+				}
+				result[key][platformImage.Platform] = problems
+			}
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// FormatLabelReport formats label problems of CheckSnapshotLabels grouped by image and platform.
+func FormatLabelReport(images map[string]string, problems map[string]map[string][]string, policy LabelPolicy) string {
+	var report strings.Builder
+	for _, key := range GetMapKeysSorted(problems) {
+		class := "no class"
+		if labelClass := policy.ClassOf(key); labelClass != nil {
+			class = labelClass.Name
+		}
+		fmt.Fprintf(&report, "%s (%s, %s):\n", key, class, images[key])
+		for _, platform := range GetMapKeysSorted(problems[key]) {
+			fmt.Fprintf(&report, "  %s:\n", platform)
+			for _, problem := range problems[key][platform] {
+				fmt.Fprintf(&report, "    %s\n", problem)
+			}
+		}
+	}
+	return report.String()
+}
@@ -0,0 +1,106 @@
+package support_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/securesign/structural-tests/test/support"
+)
+
+var _ = Describe("Label policy", func() {
+	policy := support.LabelPolicy{
+		Common: support.LabelRule{
+			Required:  []string{"build-date"},
+			Values:    map[string]string{"vendor": "Red Hat, Inc.", "architecture": support.LabelArchitecturePlaceholder},
+			Forbidden: []string{"maintainer"},
+		},
+		Classes: []support.LabelClass{
+			{
+				Name:   "operator",
+				Images: []string{"*-operator-image"},
+				LabelRule: support.LabelRule{
+					Values:   map[string]string{"vendor": "Red Hat"},
+					Patterns: map[string]string{"name": `^rhtas/.*-rhel9-operator$`},
+				},
+			},
+			{
+				Name:      "cli",
+				Images:    []string{"*-cli-image", "cosign-image"},
+				LabelRule: support.LabelRule{Required: []string{"url"}, Forbidden: []string{"io.openshift.tags"}},
+			},
+			{
+				Name:      "any operator",
+				Images:    []string{"*-operator-*"},
+				LabelRule: support.LabelRule{Required: []string{"never-applied"}},
+			},
+		},
+	}
+
+	DescribeTable("finds the first matching class",
+		func(key string, expected string) {
+			class := policy.ClassOf(key)
+			if expected == "" {
+				Expect(class).To(BeNil())
+				return
+			}
+			Expect(class).NotTo(BeNil())
+			Expect(class.Name).To(Equal(expected))
+		},
+		Entry("earlier class takes precedence", "rhtas-operator-image", "operator"),
+		Entry("later class", "rhtas-operator-bundle-image", "any operator"),
+		Entry("any pattern of a class", "cosign-image", "cli"),
+		Entry("no class", "rekor-server-image", ""),
+	)
+
+	DescribeTable("merges the common and class rules",
+		func(key string, required, forbidden []string, values, patterns map[string]string) {
+			rule := policy.RuleFor(key)
+			Expect(rule.Required).To(ConsistOf(required))
+			Expect(rule.Forbidden).To(ConsistOf(forbidden))
+			Expect(rule.Values).To(Equal(values))
+			Expect(rule.Patterns).To(Equal(patterns))
+		},
+		Entry("common rule only", "rekor-server-image", []string{"build-date"}, []string{"maintainer"},
+			map[string]string{"vendor": "Red Hat, Inc.", "architecture": "{architecture}"}, map[string]string{}),
+		Entry("class value overrides the common value", "rhtas-operator-image", []string{"build-date"}, []string{"maintainer"},
+			map[string]string{"vendor": "Red Hat", "architecture": "{architecture}"},
+			map[string]string{"name": `^rhtas/.*-rhel9-operator$`}),
+		Entry("class required and forbidden labels are added", "cosign-image",
+			[]string{"build-date", "url"}, []string{"maintainer", "io.openshift.tags"},
+			map[string]string{"vendor": "Red Hat, Inc.", "architecture": "{architecture}"}, map[string]string{}),
+	)
+
+	It("does not change the common rule", func() {
+		policy.RuleFor("cosign-image")
+		Expect(policy.Common.Required).To(Equal([]string{"build-date"}))
+		Expect(policy.Common.Values).To(HaveKeyWithValue("vendor", "Red Hat, Inc."))
+	})
+
+	DescribeTable("checks labels",
+		func(key, platform string, labels map[string]string, expected ...string) {
+			Expect(policy.CheckLabels(key, platform, labels)).To(HaveExactElements(expected))
+		},
+		Entry("valid labels", "rekor-server-image", "linux/amd64",
+			map[string]string{"build-date": "2024-05-02", "vendor": "Red Hat, Inc.", "architecture": "x86_64"}),
+		Entry("architecture placeholder expands to the platform label", "rekor-server-image", "linux/arm64",
+			map[string]string{"build-date": "2024-05-02", "vendor": "Red Hat, Inc.", "architecture": "x86_64"},
+			"architecture: x86_64, expected: aarch64"),
+		Entry("missing required and value labels", "rekor-server-image", "linux/amd64",
+			map[string]string{"architecture": "x86_64", "vendor": ""},
+			"build-date: missing", "vendor: missing"),
+		Entry("class value overrides the common value", "rhtas-operator-image", "linux/amd64",
+			map[string]string{"build-date": "2024-05-02", "vendor": "Red Hat, Inc.", "architecture": "x86_64",
+				"name": "rhtas/rhtas-rhel9-operator"},
+			"vendor: Red Hat, Inc., expected: Red Hat"),
+		Entry("pattern mismatch", "rhtas-operator-image", "linux/amd64",
+			map[string]string{"build-date": "2024-05-02", "vendor": "Red Hat", "architecture": "x86_64",
+				"name": "rhtas/operator"},
+			"name: rhtas/operator, expected to match: ^rhtas/.*-rhel9-operator$"),
+		Entry("missing pattern label", "rhtas-operator-image", "linux/amd64",
+			map[string]string{"build-date": "2024-05-02", "vendor": "Red Hat", "architecture": "x86_64"},
+			"name: missing"),
+		Entry("common and class forbidden labels", "cosign-image", "linux/amd64",
+			map[string]string{"build-date": "2024-05-02", "vendor": "Red Hat, Inc.", "architecture": "x86_64",
+				"url": "https://example.com", "maintainer": "someone", "io.openshift.tags": ""},
+			"io.openshift.tags: , forbidden", "maintainer: someone, forbidden"),
+	)
+})
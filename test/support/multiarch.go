@@ -0,0 +1,141 @@
+package support
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// PlatformPolicy lists the platforms (os/arch) snapshot images must be built for (platforms section of the suite config).
+type PlatformPolicy struct {
+	// Default platforms of every image not listed in Components.
+	Default []string `yaml:"default"`
+	// Components maps image keys (path.Match patterns allowed) to their platforms. An empty list disables the check.
+	Components map[string][]string `yaml:"components"`
+}
+
+// Expected returns the platforms expected for an image key: an exact Components entry, then the first
+// matching pattern (in sorted order), then Default.
+func (p PlatformPolicy) Expected(key string) []string {
+	if platforms, ok := p.Components[key]; ok {
+		return platforms
+	}
+	for _, pattern := range GetMapKeysSorted(p.Components) {
+		if matched, err := path.Match(pattern, key); err == nil && matched {
+			return p.Components[pattern]
+		}
+	}
+	return p.Default
+}
+
+// PlatformCheck is the platform set of one image compared to the expected platforms.
+type PlatformCheck struct {
+	Key   string
+	Image string
+	// Index is true for a manifest list (image index), false for a single manifest.
+	Index    bool
+	Expected []string
+	Actual   []string
+	Missing  []string
+	Extra    []string
+}
+
+// OK reports whether the image has exactly the expected platforms.
+func (c PlatformCheck) OK() bool {
+	return len(c.Missing) == 0 && len(c.Extra) == 0
+}
+
+// CheckImagePlatforms resolves an image digest and compares its platforms with expected. The platform
+// of a single manifest is read from the image config, which requires pulling it.
+func CheckImagePlatforms(ctx context.Context, key, imageRef string, expected []string) (PlatformCheck, error) {
+	check := PlatformCheck{Key: key, Image: imageRef, Expected: expected}
+	list, err := inspectManifestList(ctx, imageRef)
+	if err != nil {
+		return check, err
+	}
+	if len(list.Manifests) > 0 {
+		check.Index = true
+		check.Actual, err = GetManifestListPlatforms(ctx, imageRef)
+		if err != nil {
+			return check, err
+		}
+	} else {
+		platform, err := singleManifestPlatform(ctx, imageRef)
+		if err != nil {
+			return check, err
+		}
+		check.Actual = []string{platform}
+	}
+	slices.Sort(check.Actual)
+	for _, platform := range expected {
+		if !slices.Contains(check.Actual, platform) {
+			check.Missing = append(check.Missing, platform)
+		}
+	}
+	for _, platform := range check.Actual {
+		if !slices.Contains(expected, platform) {
+			check.Extra = append(check.Extra, platform)
+		}
+	}
+	return check, nil
+}
+
+// FormatPlatformMatrix renders checks as a matrix of images and platforms: "ok" for expected platforms
+// that are present, "MISSING" and "EXTRA" for differences and "-" for platforms not expected.
+func FormatPlatformMatrix(checks []PlatformCheck) string {
+	var platforms []string
+	for _, check := range checks {
+		for _, platform := range slices.Concat(check.Expected, check.Actual) {
+			if !slices.Contains(platforms, platform) {
+				platforms = append(platforms, platform)
+			}
+		}
+	}
+	slices.Sort(platforms)
+
+	var builder strings.Builder
+	table := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+	fmt.Fprintf(table, "IMAGE\tKIND\t%s\n", strings.Join(platforms, "\t"))
+	for _, check := range checks {
+		kind := "manifest"
+		if check.Index {
+			kind = "index"
+		}
+		cells := make([]string, 0, len(platforms))
+		for _, platform := range platforms {
+			switch {
+			case slices.Contains(check.Missing, platform):
+				cells = append(cells, "MISSING")
+			case slices.Contains(check.Extra, platform):
+				cells = append(cells, "EXTRA")
+			case slices.Contains(check.Actual, platform):
+				cells = append(cells, "ok")
+			default:
+				cells = append(cells, "-")
+			}
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", check.Key, kind, strings.Join(cells, "\t"))
+	}
+	_ = table.Flush()
+	return builder.String()
+}
+
+// CheckSnapshotPlatforms checks the platforms of every image with expected platforms, sorted by image key.
+func CheckSnapshotPlatforms(ctx context.Context, images map[string]string, policy PlatformPolicy) ([]PlatformCheck, error) {
+	var checks []PlatformCheck
+	for _, key := range GetMapKeysSorted(images) {
+		expected := policy.Expected(key)
+		if len(expected) == 0 {
+			continue
+		}
+		check, err := CheckImagePlatforms(ctx, key, images[key], expected)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
@@ -23,11 +23,17 @@ type ChannelEntry struct {
 
 // Bundle schema.
 type Bundle struct {
-	Schema     string     `json:"schema"`
-	Name       string     `json:"name"`
-	Package    string     `json:"package"`
-	Image      string     `json:"image"`
-	Properties []Property `json:"properties"`
+	Schema        string         `json:"schema"`
+	Name          string         `json:"name"`
+	Package       string         `json:"package"`
+	Image         string         `json:"image"`
+	Properties    []Property     `json:"properties"`
+	RelatedImages []RelatedImage `json:"relatedImages,omitempty"`
+}
+
+type RelatedImage struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
 }
 
 type Property struct {
@@ -2,7 +2,6 @@ package operator
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -15,22 +14,25 @@ import (
 	"github.com/securesign/structural-tests/test/support/pyxis"
 )
 
-const maxFreshnessAgeDays = 7
-
 //nolint:funlen,gocognit
-func DescribeOperatorImageTests(product string, defaultsData []byte) bool {
+func DescribeOperatorImageTests(product string, embeddedDefaults []byte) bool {
 	return Describe("Operator images", Ordered, func() {
 		var (
 			cfg                 OperatorConfig
 			snapshotData        support.SnapshotData
 			repositories        *support.RepositoryList
+			repositoryPolicy    support.RepositoryPolicy
+			gradePolicy         pyxis.GradePolicy
+			vulnerabilityPolicy pyxis.VulnerabilityPolicy
+			newerDigestPolicy   pyxis.NewerDigestPolicy
 			operatorImage       string
 			operatorTasImages   support.OperatorMap
 			operatorOtherImages support.OperatorMap
 		)
 
 		BeforeAll(func() {
-			var err error
+			defaultsData, err := support.SuiteDefaults(embeddedDefaults)
+			Expect(err).NotTo(HaveOccurred(), "failed to load the suite config of %s", product)
 			cfg, err = GetOperatorConfig(product, defaultsData)
 			Expect(err).NotTo(HaveOccurred(), "failed to load operator config for product %q", product)
 
@@ -41,6 +43,15 @@ func DescribeOperatorImageTests(product string, defaultsData []byte) bool {
 			repositories, err = support.LoadRepositoryList()
 			Expect(err).NotTo(HaveOccurred())
 			Expect(repositories.Data).NotTo(BeEmpty(), "No images were detected in repositories file")
+
+			repositoryPolicy, err = support.GetRepositoryPolicyFromConfig(defaultsData)
+			Expect(err).NotTo(HaveOccurred())
+			gradePolicy, err = support.GetGradePolicyFromConfig(defaultsData)
+			Expect(err).NotTo(HaveOccurred())
+			vulnerabilityPolicy, err = support.GetVulnerabilityPolicyFromConfig(defaultsData)
+			Expect(err).NotTo(HaveOccurred())
+			newerDigestPolicy, err = support.GetNewerDigestPolicyFromConfig(defaultsData)
+			Expect(err).NotTo(HaveOccurred())
 		})
 
 		It("get operator image", func() {
@@ -65,32 +76,51 @@ func DescribeOperatorImageTests(product string, defaultsData []byte) bool {
 				support.LogMap(fmt.Sprintf("Operator other images (%d):", len(operatorOtherImages)), operatorOtherImages)
 			}
 			Expect(operatorTasImages).NotTo(BeEmpty())
+			support.RecordUsedImages(support.UsedImagesOperator, support.GetMapValues(operatorTasImages)...)
+			support.RecordUsedImages(support.UsedImagesOperator, support.GetMapValues(operatorOtherImages)...)
 		})
 
 		It("operator images are listed in registry.redhat.io", func() {
 			var errs []error
 			for _, image := range operatorTasImages {
-				if repositories.FindByImage(image) == nil {
-					errs = append(errs, fmt.Errorf("%w: %s", errors.New("not found in registry"), image))
+				if err := repositories.CheckImage(image, repositoryPolicy); err != nil {
+					errs = append(errs, err)
 				}
 			}
 			Expect(errs).To(BeEmpty())
 		})
 
+		if support.IsPostGA() {
+			It("operator images are published with release tags", func() {
+				if len(repositoryPolicy.ReleaseTags) == 0 {
+					Skip("no repositories.releaseTags configured")
+				}
+				errs := support.VerifyReleaseTags(operatorTasImages, repositoryPolicy, support.GetVersion())
+				Expect(errs).To(BeEmpty(), "Some operator images are not published with release tags")
+			})
+		}
+
 		It("operator TAS images are all valid", func() {
 			Expect(support.GetMapKeys(operatorTasImages)).To(ContainElements(cfg.ImageKeys))
 			Expect(len(operatorTasImages)).To(BeNumerically("==", len(cfg.ImageKeys)))
 			Expect(operatorTasImages).To(HaveEach(MatchRegexp(support.TasImageDefinitionRegexp)))
 		})
 
-		if len(cfg.OtherImageKeys) > 0 {
-			It("operator other images are all valid", func() {
-				Expect(support.GetMapKeys(operatorOtherImages)).To(ContainElements(cfg.OtherImageKeys))
-				Expect(len(operatorOtherImages)).To(BeNumerically("==", len(cfg.OtherImageKeys)))
-				Expect(operatorOtherImages).To(HaveEach(MatchRegexp(support.OtherImageDefinitionRegexp)))
-			})
+		// cfg is loaded in BeforeAll, after the spec tree is built, so specs depending on it skip instead of
+		// being registered conditionally.
+		skipWithoutOtherImages := func() {
+			if len(cfg.OtherImageKeys) == 0 {
+				Skip("no other image keys configured for " + product)
+			}
 		}
 
+		It("operator other images are all valid", func() {
+			skipWithoutOtherImages()
+			Expect(support.GetMapKeys(operatorOtherImages)).To(ContainElements(cfg.OtherImageKeys))
+			Expect(len(operatorOtherImages)).To(BeNumerically("==", len(cfg.OtherImageKeys)))
+			Expect(operatorOtherImages).To(HaveEach(MatchRegexp(support.OtherImageDefinitionRegexp)))
+		})
+
 		It("all image hashes are also defined in releases snapshot", func() {
 			mapped := make(map[string]string)
 			for _, imageKey := range cfg.ImageKeys {
@@ -125,44 +155,71 @@ func DescribeOperatorImageTests(product string, defaultsData []byte) bool {
 			Expect(operatorTasImages).To(HaveLen(len(hashesCounts)))
 		})
 
-		if cfg.BundleImageKey != "" {
-			It("operator-bundle use the right operator", func() {
-				bundleImage := snapshotData.Images[cfg.BundleImageKey]
-				Expect(bundleImage).NotTo(BeEmpty(), "Bundle image %q not found in snapshot", cfg.BundleImageKey)
-
-				dir, err := os.MkdirTemp("", "bundle")
-				Expect(err).NotTo(HaveOccurred())
-				defer os.RemoveAll(dir)
-
-				Expect(support.FileFromImage(
-					context.Background(),
-					bundleImage,
-					cfg.BundleCSVPath, dir),
-				).To(Succeed())
-
-				csvFile := filepath.Base(cfg.BundleCSVPath)
-				fileContent, err := os.ReadFile(filepath.Join(dir, csvFile))
-				Expect(err).NotTo(HaveOccurred())
-				Expect(fileContent).NotTo(BeEmpty())
-
-				operatorHash := support.ExtractHash(snapshotData.Images[cfg.OperatorImageKey])
-				re := regexp.MustCompile(`(\w+:\s*[\w./-]+operator[\w-]*@sha256:` + operatorHash + `)`)
-				matches := re.FindAllString(string(fileContent), -1)
-				Expect(matches).NotTo(BeEmpty())
-				support.LogArray("Operator images found in operator-bundle:", matches)
-			})
-		}
+		It("operator-bundle use the right operator", func() {
+			if cfg.BundleImageKey == "" {
+				Skip("no bundle image key configured for " + product)
+			}
+			bundleImage := snapshotData.Images[cfg.BundleImageKey]
+			Expect(bundleImage).NotTo(BeEmpty(), "Bundle image %q not found in snapshot", cfg.BundleImageKey)
 
-		if len(cfg.OtherImageKeys) > 0 {
-			It("other images have acceptable grades", func() {
-				Expect(operatorOtherImages).NotTo(BeEmpty(), "No other images found to check grades for")
-				results, err := pyxis.FetchGradesForImages(operatorOtherImages)
-				Expect(err).NotTo(HaveOccurred())
-				Expect(results.NotFound).To(BeEmpty(), "Some operator other images were not found in Pyxis")
-				Expect(results.Grades).NotTo(BeEmpty())
-				errs := pyxis.ValidateGrades(results.Grades, pyxis.GradeB, maxFreshnessAgeDays)
-				Expect(errs).To(BeEmpty(), "Some operator other images have unacceptable grades")
-			})
-		}
+			dir, err := os.MkdirTemp("", "bundle")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			Expect(support.FileFromImage(
+				context.Background(),
+				bundleImage,
+				cfg.BundleCSVPath, dir),
+			).To(Succeed())
+
+			csvFile := filepath.Base(cfg.BundleCSVPath)
+			fileContent, err := os.ReadFile(filepath.Join(dir, csvFile))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fileContent).NotTo(BeEmpty())
+
+			operatorHash := support.ExtractHash(snapshotData.Images[cfg.OperatorImageKey])
+			re := regexp.MustCompile(`(\w+:\s*[\w./-]+operator[\w-]*@sha256:` + operatorHash + `)`)
+			matches := re.FindAllString(string(fileContent), -1)
+			Expect(matches).NotTo(BeEmpty())
+			support.LogArray("Operator images found in operator-bundle:", matches)
+		})
+
+		It("other images have acceptable grades", func() {
+			skipWithoutOtherImages()
+			Expect(operatorOtherImages).NotTo(BeEmpty(), "No other images found to check grades for")
+			results, err := pyxis.FetchGradesForImages(operatorOtherImages)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results.NotFound).To(BeEmpty(), "Some operator other images were not found in Pyxis")
+			Expect(results.Grades).NotTo(BeEmpty())
+			errs := pyxis.ValidateGrades(results.Grades, gradePolicy)
+			expected := support.GetExpectedGradeArchitectures(context.Background(), operatorOtherImages, gradePolicy.Platforms)
+			errs = append(errs, pyxis.ValidateArchitectureCoverage(results.Grades, expected)...)
+			Expect(errs).To(BeEmpty(), "Some operator other images have unacceptable grades")
+		})
+
+		It("other images have no fixable important vulnerabilities", func() {
+			skipWithoutOtherImages()
+			Expect(operatorOtherImages).NotTo(BeEmpty(), "No other images found to check vulnerabilities for")
+			results, err := pyxis.FetchGradesForImages(operatorOtherImages)
+			Expect(err).NotTo(HaveOccurred())
+			vulnerabilities, err := pyxis.FetchVulnerabilitiesForImages(results.Grades)
+			Expect(err).NotTo(HaveOccurred())
+			log.Printf("Operator other images vulnerabilities:\n%s", pyxis.SummarizeVulnerabilities(vulnerabilities))
+			errs := pyxis.ValidateVulnerabilities(vulnerabilities, vulnerabilityPolicy)
+			Expect(errs).To(BeEmpty(), "Some operator other images have fixable vulnerabilities")
+		})
+
+		It("other images are pinned to the latest digest of their stream", func() {
+			skipWithoutOtherImages()
+			Expect(operatorOtherImages).NotTo(BeEmpty(), "No other images found to check digests for")
+			newer, err := pyxis.FindNewerDigests(operatorOtherImages, newerDigestPolicy)
+			Expect(err).NotTo(HaveOccurred())
+			for _, digest := range newer {
+				log.Printf("Newer digest available: %s\n", digest)
+			}
+			if newerDigestPolicy.Fail {
+				Expect(newer).To(BeEmpty(), "Some operator other images have a newer digest available")
+			}
+		})
 	})
 }
@@ -0,0 +1,156 @@
+package support
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	vcsRefLabel         = "vcs-ref"
+	vcsURLLabel         = "vcs-url"
+	sourceLocationLabel = "source-location"
+)
+
+// ProvenancePolicy configures source revision checks of snapshot images (provenance section of the suite config).
+type ProvenancePolicy struct {
+	Groups []ProvenanceGroup `yaml:"groups"`
+}
+
+// ProvenanceGroup lists images built from the same sources.
+type ProvenanceGroup struct {
+	Name string `yaml:"name"`
+	// Images are image keys. Keys must be in the snapshot, path.Match patterns may match no image.
+	Images []string `yaml:"images"`
+	// SameVcsRef requires the vcs-ref label of all images to be equal.
+	SameVcsRef bool `yaml:"sameVcsRef"`
+	// VcsURL is the expected vcs-url label of all images.
+	VcsURL string `yaml:"vcsUrl"`
+	// SourceLocation is the expected source-location label of all images.
+	SourceLocation string `yaml:"sourceLocation"`
+}
+
+// ProvenanceCheck is the result of one group.
+type ProvenanceCheck struct {
+	Group string
+	// Refs are vcs-ref labels by image key.
+	Refs     map[string]string
+	Problems []string
+}
+
+// Validate checks that groups are named, have images and check something.
+func (p ProvenancePolicy) Validate() error {
+	for index, group := range p.Groups {
+		if group.Name == "" {
+			return fmt.Errorf("group %d: missing name", index)
+		}
+		if len(group.Images) == 0 {
+			return fmt.Errorf("group %s: missing images", group.Name)
+		}
+		if !group.SameVcsRef && group.VcsURL == "" && group.SourceLocation == "" {
+			return fmt.Errorf("group %s: one of sameVcsRef, vcsUrl and sourceLocation is required", group.Name)
+		}
+		for _, pattern := range group.Images {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("group %s: invalid image pattern %q: %w", group.Name, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Keys returns the snapshot image keys of the group, and explicit keys missing from the snapshot.
+func (g ProvenanceGroup) Keys(images map[string]string) ([]string, []string) {
+	var keys, missing []string
+	for _, pattern := range g.Images {
+		if !strings.ContainsAny(pattern, `*?[\`) {
+			if _, ok := images[pattern]; !ok {
+				missing = append(missing, pattern)
+			}
+		}
+	}
+	for _, key := range GetMapKeysSorted(images) {
+		if matchesAnyPattern(g.Images, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, missing
+}
+
+// Check evaluates the group on labels of its images (by image key).
+func (g ProvenanceGroup) Check(keys, missing []string, labels map[string]map[string]string) ProvenanceCheck {
+	check := ProvenanceCheck{Group: g.Name, Refs: make(map[string]string)}
+	for _, key := range missing {
+		check.Problems = append(check.Problems, key+": missing in snapshot")
+	}
+	refs := make(map[string][]string)
+	for _, key := range keys {
+		ref := labels[key][vcsRefLabel]
+		check.Refs[key] = ref
+		if ref == "" {
+			check.Problems = append(check.Problems, key+": vcs-ref missing")
+		} else {
+			refs[ref] = append(refs[ref], key)
+		}
+		for _, source := range [][2]string{{vcsURLLabel, g.VcsURL}, {sourceLocationLabel, g.SourceLocation}} {
+			label, expected := source[0], source[1]
+			if expected != "" && normalizeSourceURL(labels[key][label]) != normalizeSourceURL(expected) {
+				check.Problems = append(check.Problems, fmt.Sprintf("%s: %s %q, expected %s", key, label, labels[key][label], expected))
+			}
+		}
+	}
+	if g.SameVcsRef && len(refs) > 1 {
+		var groups []string
+		for _, ref := range GetMapKeysSorted(refs) {
+			groups = append(groups, fmt.Sprintf("%s (%s)", ref, strings.Join(refs[ref], ", ")))
+		}
+		check.Problems = append(check.Problems, "different vcs-ref: "+strings.Join(groups, "; "))
+	}
+	return check
+}
+
+// normalizeSourceURL ignores case, a trailing slash and a .git suffix of repository URLs.
+func normalizeSourceURL(url string) string {
+	url = strings.ToLower(strings.TrimSuffix(url, "/"))
+	return strings.TrimSuffix(url, ".git")
+}
+
+// CheckProvenance evaluates every group of the policy on the snapshot images.
+func CheckProvenance(images map[string]string, policy ProvenancePolicy) ([]ProvenanceCheck, error) {
+	labels := make(map[string]map[string]string)
+	var (
+		checks []ProvenanceCheck
+		errs   []error
+	)
+	for _, group := range policy.Groups {
+		keys, missing := group.Keys(images)
+		for _, key := range keys {
+			if _, ok := labels[key]; ok {
+				continue
+			}
+			imageLabels, err := InspectImageForLabels(images[key])
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to inspect labels for image %s (%s): %w", key, images[key], err))
+			}
+			labels[key] = imageLabels
+		}
+		checks = append(checks, group.Check(keys, missing, labels))
+	}
+	return checks, errors.Join(errs...)
+}
+
+// FormatProvenanceReport lists the vcs-ref of every group image and the group problems.
+func FormatProvenanceReport(checks []ProvenanceCheck) string {
+	var report strings.Builder
+	for _, check := range checks {
+		fmt.Fprintf(&report, "%s:\n", check.Group)
+		for _, key := range GetMapKeysSorted(check.Refs) {
+			fmt.Fprintf(&report, "    %-40s %s\n", key, check.Refs[key])
+		}
+		for _, problem := range check.Problems {
+			fmt.Fprintf(&report, "  ! %s\n", problem)
+		}
+	}
+	return report.String()
+}
@@ -0,0 +1,74 @@
+package support_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/securesign/structural-tests/test/support"
+)
+
+var _ = Describe("Provenance", func() {
+	images := map[string]string{
+		"rekor-server-image":         "registry.example.com/rekor-server@sha256:1",
+		"rekor-cli-image":            "registry.example.com/rekor-cli@sha256:2",
+		"backfill-redis-index-image": "registry.example.com/backfill@sha256:3",
+	}
+
+	DescribeTable("resolves group keys",
+		func(patterns []string, keys, missing []string) {
+			groupKeys, groupMissing := support.ProvenanceGroup{Images: patterns}.Keys(images)
+			Expect(groupKeys).To(HaveExactElements(keys))
+			Expect(groupMissing).To(HaveExactElements(missing))
+		},
+		Entry("explicit keys", []string{"rekor-server-image", "rekor-cli-image"},
+			[]string{"rekor-cli-image", "rekor-server-image"}, nil),
+		Entry("explicit key missing from the snapshot", []string{"rekor-server-image", "rekor-monitor-image"},
+			[]string{"rekor-server-image"}, []string{"rekor-monitor-image"}),
+		Entry("pattern", []string{"rekor-*"}, []string{"rekor-cli-image", "rekor-server-image"}, nil),
+		Entry("pattern matching nothing is not missing", []string{"rekor-*", "trillian-*"},
+			[]string{"rekor-cli-image", "rekor-server-image"}, nil),
+	)
+
+	DescribeTable("checks group labels",
+		func(group support.ProvenanceGroup, missing []string, labels map[string]map[string]string, expected ...string) {
+			keys := support.GetMapKeysSorted(labels)
+			check := group.Check(keys, missing, labels)
+			Expect(check.Group).To(Equal(group.Name))
+			Expect(check.Problems).To(HaveExactElements(expected))
+			for _, key := range keys {
+				Expect(check.Refs).To(HaveKeyWithValue(key, labels[key]["vcs-ref"]))
+			}
+		},
+		Entry("same vcs-ref", support.ProvenanceGroup{Name: "rekor", SameVcsRef: true}, nil,
+			map[string]map[string]string{"rekor-server-image": {"vcs-ref": "abc"}, "rekor-cli-image": {"vcs-ref": "abc"}}),
+		Entry("different vcs-ref", support.ProvenanceGroup{Name: "rekor", SameVcsRef: true}, nil,
+			map[string]map[string]string{
+				"rekor-server-image":         {"vcs-ref": "abc"},
+				"rekor-cli-image":            {"vcs-ref": "def"},
+				"backfill-redis-index-image": {"vcs-ref": "abc"},
+			},
+			"different vcs-ref: abc (backfill-redis-index-image, rekor-server-image); def (rekor-cli-image)"),
+		Entry("missing vcs-ref and missing key", support.ProvenanceGroup{Name: "rekor", SameVcsRef: true},
+			[]string{"rekor-monitor-image"},
+			map[string]map[string]string{"rekor-server-image": {}, "rekor-cli-image": {"vcs-ref": "abc"}},
+			"rekor-monitor-image: missing in snapshot", "rekor-server-image: vcs-ref missing"),
+		Entry("vcs-url ignores case, a trailing slash and .git",
+			support.ProvenanceGroup{Name: "rekor", VcsURL: "https://github.com/securesign/Rekor"}, nil,
+			map[string]map[string]string{
+				"rekor-server-image": {"vcs-ref": "abc", "vcs-url": "https://github.com/securesign/rekor.git"},
+				"rekor-cli-image":    {"vcs-ref": "abc", "vcs-url": "https://GitHub.com/securesign/rekor/"},
+			}),
+		Entry("different vcs-url and source-location",
+			support.ProvenanceGroup{
+				Name: "rekor", VcsURL: "https://github.com/securesign/rekor", SourceLocation: "https://github.com/securesign/rekor.git",
+			}, nil,
+			map[string]map[string]string{
+				"rekor-server-image": {
+					"vcs-ref": "abc", "vcs-url": "https://github.com/sigstore/rekor", "source-location": "https://github.com/securesign/rekor",
+				},
+			},
+			`rekor-server-image: vcs-url "https://github.com/sigstore/rekor", expected https://github.com/securesign/rekor`),
+		Entry("missing source-location", support.ProvenanceGroup{Name: "rekor", SourceLocation: "https://github.com/securesign/rekor"}, nil,
+			map[string]map[string]string{"rekor-server-image": {"vcs-ref": "abc"}},
+			`rekor-server-image: source-location "", expected https://github.com/securesign/rekor`),
+	)
+})
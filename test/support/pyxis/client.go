@@ -0,0 +1,195 @@
+package pyxis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultBaseURL  = "https://catalog.redhat.com/api/containers/v1"
+	DefaultPageSize = 10
+
+	EnvPyxisURL    = "PYXIS_URL"
+	EnvPyxisAPIKey = "PYXIS_API_KEY" // #nosec G101
+
+	defaultRequestTimeout = 30 * time.Second
+)
+
+// RetryPolicy controls how transient failures (network errors, 429 and 5xx responses) are retried.
+// Backoff doubles after every attempt, starting at InitialBackoff and capped at MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy retries a request up to 4 times over roughly 7 seconds.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4, //nolint:mnd // default policy
+		InitialBackoff: time.Second,
+		MaxBackoff:     10 * time.Second, //nolint:mnd // default policy
+	}
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff << attempt
+	if p.MaxBackoff > 0 && (delay > p.MaxBackoff || delay <= 0) {
+		return p.MaxBackoff
+	}
+	return delay
+}
+
+// Client talks to the Pyxis container API.
+type Client struct {
+	// BaseURL is the API root, e.g. https://catalog.redhat.com/api/containers/v1.
+	BaseURL string
+	// HTTPClient is used for all requests.
+	HTTPClient *http.Client
+	// Auth, when set, is applied to every request (see APIKeyAuth).
+	Auth func(req *http.Request)
+	// Retry is the retry/backoff policy for transient failures.
+	Retry RetryPolicy
+	// PageSize is the page_size used for list endpoints.
+	PageSize int
+}
+
+// NewClient returns a client for baseURL with the default retry policy and page size.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: defaultRequestTimeout},
+		Retry:      DefaultRetryPolicy(),
+		PageSize:   DefaultPageSize,
+	}
+}
+
+// NewClientFromEnv returns a client for PYXIS_URL (catalog.redhat.com when unset),
+// authenticated with PYXIS_API_KEY when set.
+func NewClientFromEnv() *Client {
+	baseURL := os.Getenv(EnvPyxisURL)
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	log.Printf("%s='%s'\n", EnvPyxisURL, baseURL)
+	client := NewClient(baseURL)
+	if apiKey := os.Getenv(EnvPyxisAPIKey); apiKey != "" {
+		log.Printf("%s=*****\n", EnvPyxisAPIKey)
+		client.Auth = APIKeyAuth(apiKey)
+	}
+	return client
+}
+
+// APIKeyAuth authenticates requests with a Pyxis API key.
+func APIKeyAuth(apiKey string) func(req *http.Request) {
+	return func(req *http.Request) {
+		req.Header.Set("X-API-KEY", apiKey)
+	}
+}
+
+var (
+	defaultClient     *Client //nolint:gochecknoglobals // lazily created from env
+	defaultClientOnce sync.Once
+)
+
+// DefaultClient returns the shared client configured from the environment (see NewClientFromEnv).
+func DefaultClient() *Client {
+	defaultClientOnce.Do(func() {
+		defaultClient = NewClientFromEnv()
+	})
+	return defaultClient
+}
+
+// errRetryable marks responses that are worth retrying.
+var errRetryable = errors.New("retryable pyxis response")
+
+// getJSON performs a GET on path with query and decodes the JSON response into target,
+// retrying transient failures according to the retry policy.
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, target interface{}) error {
+	apiURL := c.BaseURL + path
+	if len(query) > 0 {
+		apiURL += "?" + query.Encode()
+	}
+	attempts := max(c.Retry.MaxAttempts, 1)
+	var lastErr error
+	for attempt := range attempts {
+		if attempt > 0 {
+			delay := c.Retry.backoff(attempt - 1)
+			log.Printf("Retrying %s in %s (attempt %d/%d): %v\n", apiURL, delay, attempt+1, attempts, lastErr)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("pyxis request cancelled: %w", ctx.Err())
+			case <-time.After(delay):
+			}
+		}
+		body, err := c.get(ctx, apiURL)
+		if err == nil {
+			if err := json.Unmarshal(body, target); err != nil {
+				return fmt.Errorf("parse response: %w", err)
+			}
+			return nil
+		}
+		lastErr = err
+		if !errors.Is(err, errRetryable) {
+			return err
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", attempts, lastErr)
+}
+
+func (c *Client) get(ctx context.Context, apiURL string) ([]byte, error) {
+	log.Printf("Fetching %s\n", apiURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Auth != nil {
+		c.Auth(req)
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errRetryable, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: pyxis API returned %s", errRetryable, resp.Status)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, apiURL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("pyxis API returned %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	return body, nil
+}
+
+// ErrNotFound is returned when Pyxis responds with 404 Not Found.
+var ErrNotFound = errors.New("not found in pyxis")
+
+func (c *Client) pageSize() string {
+	if c.PageSize <= 0 {
+		return strconv.Itoa(DefaultPageSize)
+	}
+	return strconv.Itoa(c.PageSize)
+}
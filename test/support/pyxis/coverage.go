@@ -0,0 +1,45 @@
+package pyxis
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// RepositoryKey returns the "registry/repository" key used in GradeResults.Grades for an image reference.
+func RepositoryKey(imageRef string) string {
+	registry, repository := extractRegistryAndRepository(imageRef)
+	if registry == "" {
+		return ""
+	}
+	return registry + "/" + repository
+}
+
+// PlatformArchitecture returns the architecture of an os/arch[/variant] platform, as used by Pyxis.
+func PlatformArchitecture(platform string) string {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 { //nolint:mnd // os/arch
+		return platform
+	}
+	return parts[1]
+}
+
+// ValidateArchitectureCoverage reports expected architectures without grade data in Pyxis.
+// expected maps "registry/repository" to the architectures the image is built for; repositories
+// without expectations are not checked.
+func ValidateArchitectureCoverage(gradeResults map[string][]ImageGradeInfo, expected map[string][]string) []error {
+	var errs []error
+	for _, repo := range slices.Sorted(maps.Keys(expected)) {
+		images, ok := gradeResults[repo]
+		if !ok {
+			continue
+		}
+		for _, arch := range expected[repo] {
+			if !slices.ContainsFunc(images, func(img ImageGradeInfo) bool { return img.Architecture == arch }) {
+				errs = append(errs, fmt.Errorf("%s (arch=%s): no grade data in Pyxis", repo, arch))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,139 @@
+package pyxis
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	icalDateLayout = "20060102"
+	icalUIDBytes   = 16
+)
+
+// GradeTransition is one freshness grade period of an image architecture.
+type GradeTransition struct {
+	Repository   string     `json:"repository"`
+	Architecture string     `json:"architecture"`
+	Grade        string     `json:"grade"`
+	Start        time.Time  `json:"start"`
+	End          *time.Time `json:"end,omitempty"`
+	// Accepted is whether the grade meets the policy threshold of the repository.
+	Accepted bool `json:"accepted"`
+}
+
+// Forecast returns the grade periods of all images that have not ended before from,
+// sorted by repository, architecture and start date.
+func Forecast(gradeResults map[string][]ImageGradeInfo, policy GradePolicy, from time.Time) []GradeTransition {
+	var transitions []GradeTransition
+	for repo, images := range gradeResults {
+		threshold, _ := policy.For(repo, from)
+		for _, img := range images {
+			for _, freshness := range img.FreshnessGrades {
+				start, err := parseTime(freshness.StartDate)
+				if err != nil {
+					continue
+				}
+				transition := GradeTransition{
+					Repository:   repo,
+					Architecture: img.Architecture,
+					Grade:        freshness.Grade,
+					Start:        start,
+				}
+				if freshness.EndDate != nil {
+					end, err := parseTime(*freshness.EndDate)
+					if err != nil || !end.After(from) {
+						continue
+					}
+					transition.End = &end
+				}
+				grade, ok := ParseGrade(freshness.Grade)
+				transition.Accepted = ok && !grade.WorseThan(threshold)
+				transitions = append(transitions, transition)
+			}
+		}
+	}
+	slices.SortFunc(transitions, func(a, b GradeTransition) int {
+		return cmp.Or(
+			cmp.Compare(a.Repository, b.Repository),
+			cmp.Compare(a.Architecture, b.Architecture),
+			a.Start.Compare(b.Start),
+		)
+	})
+	return transitions
+}
+
+// WriteForecastTable writes transitions as an aligned text table.
+func WriteForecastTable(writer io.Writer, transitions []GradeTransition) error {
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+	fmt.Fprintln(table, "REPOSITORY\tARCH\tGRADE\tFROM\tUNTIL\tACCEPTED")
+	for _, transition := range transitions {
+		until := "-"
+		if transition.End != nil {
+			until = transition.End.Format(time.DateOnly)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%t\n", transition.Repository, transition.Architecture,
+			transition.Grade, transition.Start.Format(time.DateOnly), until, transition.Accepted)
+	}
+	if err := table.Flush(); err != nil {
+		return fmt.Errorf("write forecast table: %w", err)
+	}
+	return nil
+}
+
+// WriteForecastJSON writes transitions as indented JSON.
+func WriteForecastJSON(writer io.Writer, transitions []GradeTransition) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(transitions); err != nil {
+		return fmt.Errorf("write forecast json: %w", err)
+	}
+	return nil
+}
+
+// WriteForecastICal writes transitions as an iCalendar (RFC 5545) calendar with one all-day event
+// on the first day of every grade period, so grade drops show up in release planning calendars.
+func WriteForecastICal(writer io.Writer, transitions []GradeTransition, generated time.Time) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//securesign//structural-tests grade forecast//EN",
+		"CALSCALE:GREGORIAN",
+	}
+	for _, transition := range transitions {
+		summary := fmt.Sprintf("%s (%s): grade %s", transition.Repository, transition.Architecture, transition.Grade)
+		if !transition.Accepted {
+			summary += " (below threshold)"
+		}
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+transition.uid()+"@structural-tests",
+			"DTSTAMP:"+generated.UTC().Format("20060102T150405Z"),
+			"DTSTART;VALUE=DATE:"+transition.Start.Format(icalDateLayout),
+			"DTEND;VALUE=DATE:"+transition.Start.AddDate(0, 0, 1).Format(icalDateLayout),
+			"SUMMARY:"+icalEscape(summary),
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+	if _, err := io.WriteString(writer, strings.Join(lines, "\r\n")+"\r\n"); err != nil {
+		return fmt.Errorf("write forecast ical: %w", err)
+	}
+	return nil
+}
+
+func (t GradeTransition) uid() string {
+	sum := sha256.Sum256([]byte(t.Repository + "|" + t.Architecture + "|" + t.Grade + "|" + t.Start.Format(time.RFC3339)))
+	return hex.EncodeToString(sum[:icalUIDBytes])
+}
+
+func icalEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,63 @@
+package pyxis_test
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/securesign/structural-tests/test/support/pyxis"
+	"github.com/securesign/structural-tests/test/support/pyxis/pyxistest"
+)
+
+var _ = Describe("Grade forecast", func() {
+	var results *pyxis.GradeResults
+
+	BeforeEach(func() {
+		server := pyxistest.NewServer(pyxistest.DefaultFixtureDir())
+		DeferCleanup(server.Close)
+		var err error
+		results, err = pyxis.NewClient(server.URL).FetchGradesForImages(map[string]string{"nginx-image": gradeCImage})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("lists grade transitions per architecture", func() {
+		from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		transitions := pyxis.Forecast(results.Grades, pyxis.DefaultGradePolicy(), from)
+		Expect(transitions).To(HaveLen(3))
+		Expect(transitions[0].Architecture).To(Equal("amd64"))
+		Expect(transitions[2].Architecture).To(Equal("arm64"))
+		Expect(transitions[2].Grade).To(Equal("C"))
+		Expect(transitions[2].Start).To(Equal(time.Date(2024, 4, 10, 0, 0, 0, 0, time.UTC)))
+		Expect(transitions[2].Accepted).To(BeFalse())
+
+		Expect(pyxis.Forecast(results.Grades, pyxis.DefaultGradePolicy(), time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))).To(HaveLen(2))
+	})
+
+	It("exports the forecast as table, json and ical", func() {
+		transitions := pyxis.Forecast(results.Grades, pyxis.DefaultGradePolicy(), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+		var table, json, ical bytes.Buffer
+		Expect(pyxis.WriteForecastTable(&table, transitions)).To(Succeed())
+		Expect(table.String()).To(MatchRegexp(`registry.redhat.io/rhel9/nginx-124\s+arm64\s+C\s+2024-04-10\s+-\s+false`))
+		Expect(pyxis.WriteForecastJSON(&json, transitions)).To(Succeed())
+		Expect(json.String()).To(ContainSubstring(`"start": "2024-04-10T00:00:00Z"`))
+		Expect(pyxis.WriteForecastICal(&ical, transitions, time.Now())).To(Succeed())
+		Expect(ical.String()).To(ContainSubstring("DTSTART;VALUE=DATE:20240410\r\nDTEND;VALUE=DATE:20240411\r\n" +
+			"SUMMARY:registry.redhat.io/rhel9/nginx-124 (arm64): grade C (below threshold)"))
+	})
+
+	It("validates grades as of a planned date", func() {
+		policy := pyxis.DefaultGradePolicy()
+		policy.AsOf = "2024-03-01"
+		Expect(pyxis.ValidateGrades(results.Grades, policy)).To(BeEmpty())
+
+		policy.AsOf = "2024-04-05"
+		errs := pyxis.ValidateGrades(results.Grades, policy)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0]).To(MatchError(ContainSubstring("as of 2024-04-05: registry.redhat.io/rhel9/nginx-124 (arch=arm64): grade will drop to C")))
+
+		GinkgoT().Setenv(pyxis.EnvGradesAsOf, "2024-03-01")
+		Expect(pyxis.ValidateGrades(results.Grades, policy)).To(HaveLen(1))
+		Expect(pyxis.ValidateGrades(results.Grades, policy.WithEnvAsOf())).To(BeEmpty())
+	})
+})
@@ -0,0 +1,152 @@
+package pyxis
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"time"
+)
+
+const (
+	defaultGradeThreshold = GradeB
+	defaultFreshnessDays  = 7
+
+	expiresLayout = time.DateOnly
+
+	// EnvGradesAsOf (YYYY-MM-DD) evaluates grades at a planned date, e.g. GA, instead of now.
+	EnvGradesAsOf = "GRADES_AS_OF"
+)
+
+// GradePolicy is the accepted grade and look-ahead window, configured by the grades section of the suite config:
+//
+//	grades:
+//	  threshold: B
+//	  freshnessDays: 7
+//	  asOf: "2025-06-30"
+//	  platforms: [linux/amd64, linux/arm64]
+//	  overrides:
+//	    - image: registry.redhat.io/openshift4/ose-cli
+//	      threshold: C
+//	      expires: "2025-12-31"
+//	      reason: accepted by product security
+type GradePolicy struct {
+	// Threshold is the worst accepted grade.
+	Threshold Grade `yaml:"threshold"`
+	// FreshnessDays is how many days ahead a grade drop is reported.
+	FreshnessDays int `yaml:"freshnessDays"`
+	// Platforms (os/arch) expected to have grades when the platforms cannot be read from the manifest list.
+	Platforms []string `yaml:"platforms"`
+	// AsOf (YYYY-MM-DD) evaluates grades at that date instead of now, see WithEnvAsOf for GRADES_AS_OF.
+	AsOf string `yaml:"asOf"`
+	// Overrides replace the threshold or window for individual images.
+	Overrides []GradeOverride `yaml:"overrides"`
+}
+
+// GradeOverride is a per-image exception to the default policy.
+type GradeOverride struct {
+	// Image is "registry/repository" (the key of GradeResults.Grades), path.Match patterns are allowed.
+	Image string `yaml:"image"`
+	// Threshold replaces the default threshold when set.
+	Threshold Grade `yaml:"threshold"`
+	// FreshnessDays replaces the default window when set.
+	FreshnessDays *int `yaml:"freshnessDays"`
+	// Expires (YYYY-MM-DD) is the last day the override applies, empty means it never expires.
+	Expires string `yaml:"expires"`
+	// Reason documents why the exception was granted.
+	Reason string `yaml:"reason"`
+}
+
+// DefaultGradePolicy accepts grade B or better for the next 7 days.
+func DefaultGradePolicy() GradePolicy {
+	return GradePolicy{Threshold: defaultGradeThreshold, FreshnessDays: defaultFreshnessDays}
+}
+
+// Validate checks overrides have an image and a parseable expiry date.
+func (p GradePolicy) Validate() error {
+	if p.Threshold == 0 {
+		return errors.New("grades.threshold is required")
+	}
+	if _, err := p.EvaluationTime(); err != nil {
+		return err
+	}
+	for _, override := range p.Overrides {
+		if override.Image == "" {
+			return errors.New("grades.overrides entry without image")
+		}
+		if _, err := path.Match(override.Image, ""); err != nil {
+			return fmt.Errorf("grades.overrides image %q: %w", override.Image, err)
+		}
+		if override.Expires != "" {
+			if _, err := time.Parse(expiresLayout, override.Expires); err != nil {
+				return fmt.Errorf("grades.overrides %s: invalid expires %q, expected YYYY-MM-DD", override.Image, override.Expires)
+			}
+		}
+	}
+	return nil
+}
+
+// WithEnvAsOf returns the policy with AsOf replaced by GRADES_AS_OF when it is set.
+func (p GradePolicy) WithEnvAsOf() GradePolicy {
+	if asOf := os.Getenv(EnvGradesAsOf); asOf != "" {
+		p.AsOf = asOf
+	}
+	return p
+}
+
+// EvaluationTime returns the AsOf date or now when it is not set.
+func (p GradePolicy) EvaluationTime() (time.Time, error) {
+	asOf := p.AsOf
+	if asOf == "" {
+		return time.Now().UTC(), nil
+	}
+	at, err := time.Parse(time.DateOnly, asOf)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid grades as-of date %q, expected YYYY-MM-DD", asOf)
+	}
+	return at.UTC(), nil
+}
+
+func (p GradePolicy) asOfSet() bool {
+	return p.AsOf != ""
+}
+
+// For returns the threshold and freshness window for repo ("registry/repository") at the given time.
+// The first matching override that has not expired wins.
+func (p GradePolicy) For(repo string, now time.Time) (Grade, int) {
+	threshold, days := p.Threshold, p.FreshnessDays
+	for _, override := range p.Overrides {
+		if matched, err := path.Match(override.Image, repo); err != nil || !matched {
+			continue
+		}
+		if override.expired(now) {
+			log.Printf("Grade override for %s expired on %s, using default policy\n", repo, override.Expires)
+			continue
+		}
+		if override.Threshold != 0 {
+			threshold = override.Threshold
+		}
+		if override.FreshnessDays != nil {
+			days = *override.FreshnessDays
+		}
+		return threshold, days
+	}
+	return threshold, days
+}
+
+func (o GradeOverride) expired(now time.Time) bool {
+	return isExpired(o.Expires, now)
+}
+
+// isExpired reports whether now is after the expires day (YYYY-MM-DD). Empty never expires, invalid always does.
+func isExpired(expires string, now time.Time) bool {
+	if expires == "" {
+		return false
+	}
+	lastDay, err := time.Parse(expiresLayout, expires)
+	if err != nil {
+		return true
+	}
+	return !now.Before(lastDay.AddDate(0, 0, 1))
+}
@@ -2,17 +2,16 @@ package pyxis
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
-)
 
-const baseURL = "https://catalog.redhat.com/api/containers/v1"
+	"gopkg.in/yaml.v3"
+)
 
 // Grade represents a Red Hat container image freshness grade (A through F).
 type Grade byte
@@ -39,6 +38,16 @@ func (g Grade) String() string {
 	return string(g)
 }
 
+// UnmarshalYAML parses a grade letter, so grades can be used in the suite config.
+func (g *Grade) UnmarshalYAML(value *yaml.Node) error {
+	grade, ok := ParseGrade(strings.ToUpper(value.Value))
+	if !ok {
+		return fmt.Errorf("invalid grade %q at line %d, expected A-F", value.Value, value.Line)
+	}
+	*g = grade
+	return nil
+}
+
 // WorseThan returns true when g is strictly worse than other.
 func (g Grade) WorseThan(other Grade) bool {
 	return g > other
@@ -66,12 +75,18 @@ type ImageGradeInfo struct {
 }
 
 type imagesResponse struct {
-	Data []ImageGradeInfo `json:"data"`
+	Data  []ImageGradeInfo `json:"data"`
+	Total int              `json:"total"`
 }
 
 // CurrentGrade returns the currently active grade based on the freshness_grades schedule.
 func (img ImageGradeInfo) CurrentGrade() string {
-	now := time.Now().UTC()
+	return img.GradeAt(time.Now())
+}
+
+// GradeAt returns the grade active at the given time based on the freshness_grades schedule.
+func (img ImageGradeInfo) GradeAt(at time.Time) string {
+	now := at.UTC()
 	for _, freshness := range img.FreshnessGrades {
 		start, err := parseTime(freshness.StartDate)
 		if err != nil || start.After(now) {
@@ -103,48 +118,40 @@ func parseTime(value string) (time.Time, error) {
 // identified by its manifest digest. Returns per-architecture grade data.
 // Tries manifest_list_digest first, then falls back to manifest_schema2_digest.
 func FetchImageGrades(digest string) ([]ImageGradeInfo, error) {
-	grades, err := fetchByDigestFilter("repositories.manifest_list_digest", digest)
+	return DefaultClient().FetchImageGrades(digest)
+}
+
+// FetchImageGrades is the Client variant of the package-level FetchImageGrades.
+func (c *Client) FetchImageGrades(digest string) ([]ImageGradeInfo, error) {
+	grades, err := c.fetchByDigestFilter("repositories.manifest_list_digest", digest)
 	if err != nil {
 		return nil, err
 	}
 	if len(grades) > 0 {
 		return grades, nil
 	}
-	return fetchByDigestFilter("repositories.manifest_schema2_digest", digest)
+	return c.fetchByDigestFilter("repositories.manifest_schema2_digest", digest)
 }
 
-func fetchByDigestFilter(field, digest string) ([]ImageGradeInfo, error) {
-	apiURL := fmt.Sprintf("%s/images?filter=%s==%s&include=data._id,data.architecture,data.freshness_grades&page_size=10",
-		baseURL, field, digest)
-	log.Printf("Fetching image grades from %s\n", apiURL)
-
-	const requestTimeout = 30 * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("fetch image grades: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("pyxis API returned %s", resp.Status)
-	}
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("read response: %w", err)
-	}
+// fetchByDigestFilter returns the images of a digest, following pagination.
+func (c *Client) fetchByDigestFilter(field, digest string) ([]ImageGradeInfo, error) {
+	var grades []ImageGradeInfo
+	for page := 0; ; page++ {
+		query := url.Values{}
+		query.Set("filter", field+"=="+digest)
+		query.Set("include", "data._id,data.architecture,data.freshness_grades,total")
+		query.Set("page_size", c.pageSize())
+		query.Set("page", strconv.Itoa(page))
 
-	var result imagesResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("parse response: %w", err)
+		var result imagesResponse
+		if err := c.getJSON(context.Background(), "/images", query, &result); err != nil {
+			return nil, fmt.Errorf("fetch image grades: %w", err)
+		}
+		grades = append(grades, result.Data...)
+		if len(result.Data) == 0 || len(grades) >= result.Total {
+			return grades, nil
+		}
 	}
-	return result.Data, nil
 }
 
 func extractDigest(imageRef string) (string, error) {
@@ -178,6 +185,11 @@ type GradeResults struct {
 // FetchGradesForImages fetches Pyxis grade data for each unique image digest found in the
 // image map. Images not found in Pyxis are recorded in GradeResults.NotFound.
 func FetchGradesForImages(images map[string]string) (*GradeResults, error) {
+	return DefaultClient().FetchGradesForImages(images)
+}
+
+// FetchGradesForImages is the Client variant of the package-level FetchGradesForImages.
+func (c *Client) FetchGradesForImages(images map[string]string) (*GradeResults, error) {
 	res := &GradeResults{Grades: make(map[string][]ImageGradeInfo)}
 	seen := make(map[string]bool)
 	for key, imageRef := range images {
@@ -190,7 +202,7 @@ func FetchGradesForImages(images map[string]string) (*GradeResults, error) {
 		}
 		seen[digest] = true
 
-		grades, err := FetchImageGrades(digest)
+		grades, err := c.FetchImageGrades(digest)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch grades for %s (%s): %w", key, imageRef, err)
 		}
@@ -209,14 +221,17 @@ func FetchGradesForImages(images map[string]string) (*GradeResults, error) {
 	return res, nil
 }
 
-// ValidateGrades checks that no image will have a grade worse than threshold
-// at any point between now and now+days.
-func ValidateGrades(gradeResults map[string][]ImageGradeInfo, threshold Grade, days int) []error {
+// ValidateGrades checks that no image will have a grade worse than its policy threshold
+// at any point between the policy evaluation time (now, or its as-of date) and freshnessDays later.
+func ValidateGrades(gradeResults map[string][]ImageGradeInfo, policy GradePolicy) []error {
+	now, err := policy.EvaluationTime()
+	if err != nil {
+		return []error{err}
+	}
 	var errs []error
-	deadline := time.Now().UTC().AddDate(0, 0, days)
-	now := time.Now().UTC()
-
 	for repo, images := range gradeResults {
+		threshold, days := policy.For(repo, now)
+		deadline := now.AddDate(0, 0, days)
 		for _, img := range images {
 			for _, freshness := range img.FreshnessGrades {
 				if err := checkFreshness(freshness, threshold, now, deadline, repo, img.Architecture); err != nil {
@@ -225,6 +240,11 @@ func ValidateGrades(gradeResults map[string][]ImageGradeInfo, threshold Grade, d
 			}
 		}
 	}
+	if policy.asOfSet() {
+		for i, err := range errs {
+			errs[i] = fmt.Errorf("as of %s: %w", now.Format(time.DateOnly), err)
+		}
+	}
 	return errs
 }
 
@@ -0,0 +1,112 @@
+package pyxis_test
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/securesign/structural-tests/test/support/pyxis"
+	"github.com/securesign/structural-tests/test/support/pyxis/pyxistest"
+	"gopkg.in/yaml.v3"
+)
+
+var ( //nolint:gochecknoglobals // test fixtures
+	gradeAImage  = "registry.redhat.io/openshift4/ose-cli@sha256:" + strings.Repeat("a", 64)
+	gradeCImage  = "registry.redhat.io/rhel9/nginx-124@sha256:" + strings.Repeat("b", 64)
+	unknownImage = "registry.redhat.io/ubi9/httpd-24@sha256:" + strings.Repeat("f", 64)
+)
+
+var _ = Describe("Grades", func() {
+	var (
+		server *pyxistest.Server
+		client *pyxis.Client
+	)
+
+	BeforeEach(func() {
+		server = pyxistest.NewServer(pyxistest.DefaultFixtureDir())
+		DeferCleanup(server.Close)
+		client = pyxis.NewClient(server.URL)
+		client.Retry = pyxis.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	})
+
+	It("fetches per-architecture grades by digest", func() {
+		results, err := client.FetchGradesForImages(map[string]string{
+			"ose-cli-image": gradeAImage,
+			"nginx-image":   gradeCImage,
+			"httpd-image":   unknownImage,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results.Grades).To(HaveKey("registry.redhat.io/openshift4/ose-cli"))
+		Expect(results.Grades["registry.redhat.io/openshift4/ose-cli"]).To(HaveLen(2))
+		Expect(results.NotFound).To(ConsistOf("registry.redhat.io/ubi9/httpd-24"))
+	})
+
+	It("fetches every page of per-architecture grades", func() {
+		client.PageSize = 1
+		results, err := client.FetchGradesForImages(map[string]string{"ose-cli-image": gradeAImage})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results.Grades["registry.redhat.io/openshift4/ose-cli"]).To(HaveLen(2))
+	})
+
+	It("reports images below the threshold per architecture", func() {
+		results, err := client.FetchGradesForImages(map[string]string{"ose-cli-image": gradeAImage, "nginx-image": gradeCImage})
+		Expect(err).NotTo(HaveOccurred())
+		errs := pyxis.ValidateGrades(results.Grades, pyxis.DefaultGradePolicy())
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0]).To(MatchError(ContainSubstring("registry.redhat.io/rhel9/nginx-124 (arch=arm64): current grade is C")))
+	})
+
+	It("applies per-image overrides until they expire", func() {
+		results, err := client.FetchGradesForImages(map[string]string{"nginx-image": gradeCImage})
+		Expect(err).NotTo(HaveOccurred())
+
+		policy := pyxis.DefaultGradePolicy()
+		policy.Overrides = []pyxis.GradeOverride{{Image: "registry.redhat.io/rhel9/nginx-*", Threshold: pyxis.GradeC}}
+		Expect(pyxis.ValidateGrades(results.Grades, policy)).To(BeEmpty())
+
+		policy.Overrides[0].Expires = time.Now().AddDate(0, 0, -1).Format(time.DateOnly)
+		Expect(pyxis.ValidateGrades(results.Grades, policy)).To(HaveLen(1))
+	})
+
+	It("parses the grade policy from yaml", func() {
+		policy := pyxis.DefaultGradePolicy()
+		Expect(yaml.Unmarshal([]byte("overrides:\n  - image: registry.redhat.io/openshift4/ose-cli\n    threshold: c\n    freshnessDays: 0\n"), &policy)).To(Succeed())
+		Expect(policy.Validate()).To(Succeed())
+		Expect(policy.Threshold).To(Equal(pyxis.GradeB))
+		threshold, days := policy.For("registry.redhat.io/openshift4/ose-cli", time.Now())
+		Expect(threshold).To(Equal(pyxis.GradeC))
+		Expect(days).To(BeZero())
+
+		Expect(yaml.Unmarshal([]byte("threshold: X"), &policy)).To(MatchError(ContainSubstring("invalid grade")))
+	})
+
+	It("reports expected architectures without grade data", func() {
+		results, err := client.FetchGradesForImages(map[string]string{"ose-cli-image": gradeAImage})
+		Expect(err).NotTo(HaveOccurred())
+		repo := pyxis.RepositoryKey(gradeAImage)
+		Expect(repo).To(Equal("registry.redhat.io/openshift4/ose-cli"))
+
+		Expect(pyxis.ValidateArchitectureCoverage(results.Grades, map[string][]string{repo: {"amd64", "arm64"}})).To(BeEmpty())
+		errs := pyxis.ValidateArchitectureCoverage(results.Grades, map[string][]string{
+			repo: {"amd64", pyxis.PlatformArchitecture("linux/ppc64le")},
+		})
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0]).To(MatchError("registry.redhat.io/openshift4/ose-cli (arch=ppc64le): no grade data in Pyxis"))
+	})
+
+	It("retries transient failures", func() {
+		server.FailNext(2, http.StatusServiceUnavailable)
+		grades, err := client.FetchImageGrades("sha256:" + strings.Repeat("a", 64))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(grades).To(HaveLen(2))
+		Expect(server.Requests()).To(Equal(3))
+	})
+
+	It("gives up when failures outlast the retry policy", func() {
+		server.FailNext(3, http.StatusServiceUnavailable)
+		_, err := client.FetchImageGrades("sha256:" + strings.Repeat("a", 64))
+		Expect(err).To(MatchError(ContainSubstring("503")))
+	})
+})
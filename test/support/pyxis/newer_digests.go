@@ -0,0 +1,259 @@
+package pyxis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TaggedImage is a Pyxis image with the repositories and tags it is published to.
+type TaggedImage struct {
+	ID              string            `json:"_id"` //nolint:tagliatelle // Pyxis API uses _id
+	Architecture    string            `json:"architecture"`
+	FreshnessGrades []FreshnessGrade  `json:"freshness_grades"` //nolint:tagliatelle // Pyxis API uses snake_case
+	Repositories    []ImageRepository `json:"repositories"`
+}
+
+// ImageRepository is a repository an image is published to.
+type ImageRepository struct {
+	Registry              string     `json:"registry"`
+	Repository            string     `json:"repository"`
+	ManifestListDigest    string     `json:"manifest_list_digest"`    //nolint:tagliatelle // Pyxis API uses snake_case
+	ManifestSchema2Digest string     `json:"manifest_schema2_digest"` //nolint:tagliatelle // Pyxis API uses snake_case
+	PushDate              string     `json:"push_date"`               //nolint:tagliatelle // Pyxis API uses snake_case
+	Published             bool       `json:"published"`
+	Tags                  []ImageTag `json:"tags"`
+}
+
+// ImageTag is a tag of an image in a repository.
+type ImageTag struct {
+	Name string `json:"name"`
+}
+
+// Digest returns the manifest list digest, or the manifest digest of a single-arch image.
+func (r ImageRepository) Digest() string {
+	if r.ManifestListDigest != "" {
+		return r.ManifestListDigest
+	}
+	return r.ManifestSchema2Digest
+}
+
+type taggedImagesResponse struct {
+	Data  []TaggedImage `json:"data"`
+	Total int           `json:"total"`
+}
+
+const taggedImageInclude = "data._id,data.architecture,data.freshness_grades,data.repositories.registry," +
+	"data.repositories.repository,data.repositories.manifest_list_digest,data.repositories.manifest_schema2_digest," +
+	"data.repositories.push_date,data.repositories.published,data.repositories.tags.name"
+
+// FetchImagesByDigest returns the per-architecture images of a digest with their repositories and tags.
+func (c *Client) FetchImagesByDigest(digest string) ([]TaggedImage, error) {
+	for _, field := range []string{"repositories.manifest_list_digest", "repositories.manifest_schema2_digest"} {
+		images, err := c.fetchTaggedImagesByDigestFilter(field, digest)
+		if err != nil {
+			return nil, fmt.Errorf("fetch images of %s: %w", digest, err)
+		}
+		if len(images) > 0 {
+			return images, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *Client) fetchTaggedImagesByDigestFilter(field, digest string) ([]TaggedImage, error) {
+	var images []TaggedImage
+	for page := 0; ; page++ {
+		query := url.Values{}
+		query.Set("filter", field+"=="+digest)
+		query.Set("include", taggedImageInclude+",total")
+		query.Set("page_size", c.pageSize())
+		query.Set("page", strconv.Itoa(page))
+
+		var result taggedImagesResponse
+		if err := c.getJSON(context.Background(), "/images", query, &result); err != nil {
+			return nil, err
+		}
+		images = append(images, result.Data...)
+		if len(result.Data) == 0 || len(images) >= result.Total {
+			return images, nil
+		}
+	}
+}
+
+// FetchImagesByTag returns the per-architecture images the tag currently points to.
+func (c *Client) FetchImagesByTag(registry, repository, tag string) ([]TaggedImage, error) {
+	apiPath := "/repositories/registry/" + url.PathEscape(registry) + "/repository/" + repository + "/tag/" + url.PathEscape(tag)
+	query := url.Values{}
+	query.Set("include", taggedImageInclude)
+	query.Set("page_size", c.pageSize())
+
+	var result taggedImagesResponse
+	if err := c.getJSON(context.Background(), apiPath, query, &result); err != nil {
+		return nil, fmt.Errorf("fetch images of %s/%s:%s: %w", registry, repository, tag, err)
+	}
+	return result.Data, nil
+}
+
+// NewerDigestPolicy configures the newer digest check (newerDigests section of the suite config).
+type NewerDigestPolicy struct {
+	// IgnoreTags are tags not considered the stream of a pinned image, e.g. latest pointing to a newer major version.
+	IgnoreTags []string `yaml:"ignoreTags"`
+	// Fail turns newer digests into a failure, otherwise they are only reported.
+	Fail bool `yaml:"fail"`
+}
+
+// DefaultNewerDigestPolicy fails on newer digests of any tag but latest.
+func DefaultNewerDigestPolicy() NewerDigestPolicy {
+	return NewerDigestPolicy{IgnoreTags: []string{"latest"}, Fail: true}
+}
+
+// NewerDigest is a newer build of the stream (tag) a pinned image was published with.
+type NewerDigest struct {
+	// Key is the image key, e.g. ose-cli-image.
+	Key          string
+	Image        string
+	Repository   string
+	Tag          string
+	PinnedDigest string
+	NewerDigest  string
+	// Grade is the current grade of the newer image (of its first architecture).
+	Grade    string
+	PushDate string
+}
+
+func (n NewerDigest) String() string {
+	return fmt.Sprintf("%s (%s): tag %s moved from %s to %s (grade %s, pushed %s)",
+		n.Key, n.Repository, n.Tag, n.PinnedDigest, n.NewerDigest, n.Grade, n.PushDate)
+}
+
+// FindNewerDigests looks up the tags every pinned image is published with and reports tags
+// that now point to a different digest. Floating tags are usually moved away from older builds in Pyxis,
+// so the version part of build tags ("1-60" is a build of stream "1") is checked as well.
+func FindNewerDigests(images map[string]string, policy NewerDigestPolicy) ([]NewerDigest, error) {
+	return DefaultClient().FindNewerDigests(images, policy)
+}
+
+// FindNewerDigests is the Client variant of the package-level FindNewerDigests.
+func (c *Client) FindNewerDigests(images map[string]string, policy NewerDigestPolicy) ([]NewerDigest, error) {
+	var newer []NewerDigest
+	for _, key := range slices.Sorted(maps.Keys(images)) {
+		imageRef := images[key]
+		digest, err := extractDigest(imageRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract digest for %s (%s): %w", key, imageRef, err)
+		}
+		_, repository := extractRegistryAndRepository(imageRef)
+		pinned, err := c.FetchImagesByDigest(digest)
+		if err != nil {
+			return nil, err
+		}
+		if len(pinned) == 0 {
+			log.Printf("Not found in Pyxis: %s\n", imageRef)
+			continue
+		}
+		for _, stream := range streams(pinned, repository, policy.IgnoreTags) {
+			current, err := c.FetchImagesByTag(stream.registry, repository, stream.tag)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if found, ok := newerImage(current, pinned, repository); ok {
+				found.Key, found.Image, found.Tag, found.PinnedDigest = key, imageRef, stream.tag, digest
+				newer = append(newer, found)
+			}
+		}
+	}
+	return newer, nil
+}
+
+type stream struct {
+	registry string
+	tag      string
+}
+
+// streams returns the distinct registry/tag pairs of repository the pinned images are published with.
+func streams(pinned []TaggedImage, repository string, ignoreTags []string) []stream {
+	var result []stream
+	for _, img := range pinned {
+		for _, repo := range img.Repositories {
+			if repo.Repository != repository {
+				continue
+			}
+			for _, tag := range repo.Tags {
+				for _, name := range []string{tag.Name, streamTag(tag.Name)} {
+					candidate := stream{registry: repo.Registry, tag: name}
+					if name != "" && !slices.Contains(ignoreTags, name) && !slices.Contains(result, candidate) {
+						result = append(result, candidate)
+					}
+				}
+			}
+		}
+	}
+	return result
+}
+
+// streamTag returns the version of a <version>-<release> build tag, or "" for other tags.
+func streamTag(tag string) string {
+	if index := strings.LastIndex(tag, "-"); index > 0 {
+		return tag[:index]
+	}
+	return ""
+}
+
+// newerImage returns the first image of repository in current that is none of the pinned images and was pushed
+// after them. The pinned digest may be a manifest list or, for arch-pinned images, a single manifest, so both
+// digests of the pinned images are compared.
+func newerImage(current, pinned []TaggedImage, repository string) (NewerDigest, bool) {
+	pinnedDigests, pinnedPushDate := pinnedDigestsAndPushDate(pinned, repository)
+	for _, img := range current {
+		for _, repo := range img.Repositories {
+			if repo.Repository != repository || repo.Digest() == "" ||
+				pinnedDigests[strings.ToLower(repo.ManifestListDigest)] || pinnedDigests[strings.ToLower(repo.ManifestSchema2Digest)] {
+				continue
+			}
+			if pushDate, err := time.Parse(time.RFC3339, repo.PushDate); !pinnedPushDate.IsZero() && (err != nil || !pushDate.After(pinnedPushDate)) {
+				continue
+			}
+			return NewerDigest{
+				Repository:  repo.Registry + "/" + repo.Repository,
+				NewerDigest: repo.Digest(),
+				Grade:       ImageGradeInfo{FreshnessGrades: img.FreshnessGrades}.CurrentGrade(),
+				PushDate:    repo.PushDate,
+			}, true
+		}
+	}
+	return NewerDigest{}, false
+}
+
+// pinnedDigestsAndPushDate returns the (lowercase) digests of the pinned images in repository and their latest push date,
+// zero when unknown.
+func pinnedDigestsAndPushDate(pinned []TaggedImage, repository string) (map[string]bool, time.Time) {
+	digests := make(map[string]bool)
+	var pushDate time.Time
+	for _, img := range pinned {
+		for _, repo := range img.Repositories {
+			if repo.Repository != repository {
+				continue
+			}
+			for _, digest := range []string{repo.ManifestListDigest, repo.ManifestSchema2Digest} {
+				if digest != "" {
+					digests[strings.ToLower(digest)] = true
+				}
+			}
+			if date, err := time.Parse(time.RFC3339, repo.PushDate); err == nil && date.After(pushDate) {
+				pushDate = date
+			}
+		}
+	}
+	return digests, pushDate
+}
@@ -0,0 +1,67 @@
+package pyxis_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/securesign/structural-tests/test/support/pyxis"
+	"github.com/securesign/structural-tests/test/support/pyxis/pyxistest"
+)
+
+var _ = Describe("Newer digests", func() {
+	var client *pyxis.Client
+
+	BeforeEach(func() {
+		server := pyxistest.NewServer(pyxistest.DefaultFixtureDir())
+		DeferCleanup(server.Close)
+		client = pyxis.NewClient(server.URL)
+	})
+
+	It("reports a newer build of the stream of a pinned image", func() {
+		newer, err := client.FindNewerDigests(map[string]string{
+			"nginx-image":   gradeCImage,
+			"ose-cli-image": gradeAImage,
+			"httpd-image":   unknownImage,
+		}, pyxis.DefaultNewerDigestPolicy())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newer).To(HaveLen(1))
+		Expect(newer[0].Key).To(Equal("nginx-image"))
+		Expect(newer[0].Repository).To(Equal("registry.access.redhat.com/rhel9/nginx-124"))
+		Expect(newer[0].Tag).To(Equal("1"))
+		Expect(newer[0].NewerDigest).To(Equal("sha256:" + strings.Repeat("c", 64)))
+		Expect(newer[0].Grade).To(Equal("A"))
+		Expect(newer[0].String()).To(ContainSubstring("tag 1 moved from sha256:bbbb"))
+	})
+
+	It("skips ignored tags", func() {
+		policy := pyxis.DefaultNewerDigestPolicy()
+		policy.IgnoreTags = append(policy.IgnoreTags, "1")
+		newer, err := client.FindNewerDigests(map[string]string{"nginx-image": gradeCImage}, policy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newer).To(BeEmpty())
+	})
+
+	It("does not report the manifest list of an arch-pinned image", func() {
+		newer, err := client.FindNewerDigests(map[string]string{
+			"ose-cli-image": "registry.redhat.io/openshift4/ose-cli@sha256:" + strings.Repeat("3", 64),
+		}, pyxis.DefaultNewerDigestPolicy())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newer).To(BeEmpty())
+	})
+
+	It("does not report a stream tag pushed before the pinned image", func() {
+		newer, err := client.FindNewerDigests(map[string]string{
+			"nginx-image": "registry.redhat.io/rhel9/nginx-124@sha256:" + strings.Repeat("d", 64),
+		}, pyxis.DefaultNewerDigestPolicy())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newer).To(BeEmpty())
+	})
+
+	It("fetches every page of the pinned images", func() {
+		client.PageSize = 1
+		images, err := client.FetchImagesByDigest("sha256:" + strings.Repeat("b", 64))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(images).To(HaveLen(2))
+	})
+})
@@ -0,0 +1,13 @@
+package pyxis_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPyxis(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pyxis Suite")
+}
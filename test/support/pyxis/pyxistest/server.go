@@ -0,0 +1,194 @@
+// Package pyxistest provides an in-process fake Pyxis API driven by fixture files,
+// so Pyxis based checks can be exercised offline.
+//
+// Fixtures are JSON files laid out like the API paths they answer:
+//
+//	images/<digest>.json                 response of /images?filter=repositories.<digest field>==<digest>
+//	product-listings/<id>.json           response of /product-listings/id/<id>/repositories
+//	vulnerabilities/<image id>.json      response of /images/id/<image id>/vulnerabilities
+//	tags/<registry>/<repository>/<tag>.json
+//	                                     response of /repositories/registry/<registry>/repository/<repository>/tag/<tag>
+//
+// Digests are stored with ":" replaced by "-" (sha256-abc...). Unknown images and vulnerabilities return an
+// empty page, unknown tags return 404 Not Found like Pyxis.
+package pyxistest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	testroot "github.com/securesign/structural-tests/test"
+)
+
+// DefaultFixtureDir returns testdata/pyxis of this repository.
+func DefaultFixtureDir() string {
+	return filepath.Join(testroot.GetRootPath(), "testdata", "pyxis")
+}
+
+// Server is a fake Pyxis API. Use URL as the client base URL.
+type Server struct {
+	*httptest.Server
+
+	fixtureDir string
+
+	mutex        sync.Mutex
+	failures     int
+	failStatus   int
+	requestCount int
+}
+
+// NewServer starts a fake Pyxis API serving fixtures from fixtureDir. Close it when done.
+func NewServer(fixtureDir string) *Server {
+	server := &Server{fixtureDir: fixtureDir}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.handle))
+	return server
+}
+
+// FailNext makes the next count requests fail with the given HTTP status, e.g. to exercise retries.
+func (s *Server) FailNext(count, status int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.failures = count
+	s.failStatus = status
+}
+
+// Requests returns the number of requests received so far.
+func (s *Server) Requests() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requestCount
+}
+
+var (
+	digestFilterRegexp          = regexp.MustCompile(`^repositories\.[\w.]+==(sha256:[a-f0-9]+)$`)
+	productListingPathRegexp    = regexp.MustCompile(`^/product-listings/id/([\w-]+)/repositories$`)
+	vulnerabilitiesPathRegexp   = regexp.MustCompile(`^/images/id/(\w+)/vulnerabilities$`)
+	tagPathRegexp               = regexp.MustCompile(`^/repositories/registry/([^/]+)/repository/(.+)/tag/([^/]+)$`)
+	releaseCategoryFilterRegexp = regexp.MustCompile(`^release_categories=in=\((.*)\)$`)
+)
+
+func (s *Server) handle(writer http.ResponseWriter, req *http.Request) {
+	if status := s.nextFailure(); status != 0 {
+		http.Error(writer, http.StatusText(status), status)
+		return
+	}
+	switch {
+	case req.URL.Path == "/images":
+		match := digestFilterRegexp.FindStringSubmatch(req.URL.Query().Get("filter"))
+		if match == nil {
+			http.Error(writer, "unsupported filter", http.StatusBadRequest)
+			return
+		}
+		s.servePage(writer, req, filepath.Join("images", fixtureName(match[1])), nil)
+	case productListingPathRegexp.MatchString(req.URL.Path):
+		id := productListingPathRegexp.FindStringSubmatch(req.URL.Path)[1]
+		s.servePage(writer, req, filepath.Join("product-listings", id), releaseCategoryFilter(req.URL.Query().Get("filter")))
+	case vulnerabilitiesPathRegexp.MatchString(req.URL.Path):
+		id := vulnerabilitiesPathRegexp.FindStringSubmatch(req.URL.Path)[1]
+		s.servePage(writer, req, filepath.Join("vulnerabilities", id), nil)
+	case tagPathRegexp.MatchString(req.URL.Path):
+		match := tagPathRegexp.FindStringSubmatch(req.URL.Path)
+		fixture := filepath.Join("tags", match[1], match[2], match[3])
+		if _, err := os.Stat(filepath.Join(s.fixtureDir, fixture+".json")); err != nil {
+			http.NotFound(writer, req)
+			return
+		}
+		s.servePage(writer, req, fixture, nil)
+	default:
+		http.NotFound(writer, req)
+	}
+}
+
+func (s *Server) nextFailure() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.requestCount++
+	if s.failures == 0 {
+		return 0
+	}
+	s.failures--
+	return s.failStatus
+}
+
+// servePage answers with the "data" of the fixture kept by keep (all when nil), paginated with
+// page and page_size. A missing fixture is an empty page, like Pyxis answers unknown digests.
+func (s *Server) servePage(writer http.ResponseWriter, req *http.Request, fixture string, keep func(json.RawMessage) bool) {
+	var page struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	if err := s.readFixture(fixture, &page); err != nil && !errors.Is(err, os.ErrNotExist) {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if keep != nil {
+		page.Data = slices.DeleteFunc(page.Data, func(entry json.RawMessage) bool { return !keep(entry) })
+	}
+	pageNumber, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	pageSize, err := strconv.Atoi(req.URL.Query().Get("page_size"))
+	if err != nil || pageSize <= 0 {
+		pageSize = len(page.Data)
+	}
+	start := min(pageNumber*pageSize, len(page.Data))
+	end := min(start+pageSize, len(page.Data))
+	s.writeJSON(writer, map[string]interface{}{
+		"data":      page.Data[start:end],
+		"page":      pageNumber,
+		"page_size": pageSize,
+		"total":     len(page.Data),
+	})
+}
+
+func (s *Server) readFixture(fixture string, target interface{}) error {
+	content, err := os.ReadFile(filepath.Join(s.fixtureDir, fixture+".json"))
+	if err != nil {
+		return fmt.Errorf("read fixture %s: %w", fixture, err)
+	}
+	if err := json.Unmarshal(content, target); err != nil {
+		return fmt.Errorf("parse fixture %s: %w", fixture, err)
+	}
+	return nil
+}
+
+func (s *Server) writeJSON(writer http.ResponseWriter, body interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(writer).Encode(body); err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// releaseCategoryFilter supports the release_categories=in=(...) filter of product listing repositories.
+func releaseCategoryFilter(filter string) func(json.RawMessage) bool {
+	match := releaseCategoryFilterRegexp.FindStringSubmatch(filter)
+	if match == nil {
+		return nil
+	}
+	var wanted []string
+	for _, category := range strings.Split(match[1], ",") {
+		wanted = append(wanted, strings.Trim(strings.TrimSpace(category), `"`))
+	}
+	return func(entry json.RawMessage) bool {
+		var repository struct {
+			ReleaseCategories []string `json:"release_categories"` //nolint:tagliatelle // Pyxis API uses snake_case
+		}
+		if err := json.Unmarshal(entry, &repository); err != nil {
+			return false
+		}
+		return slices.ContainsFunc(repository.ReleaseCategories, func(category string) bool {
+			return slices.Contains(wanted, category)
+		})
+	}
+}
+
+func fixtureName(value string) string {
+	return strings.ReplaceAll(value, ":", "-")
+}
@@ -0,0 +1,78 @@
+package pyxis
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrTagNotFound    = errors.New("tag not found in pyxis")
+	ErrTagMismatch    = errors.New("tag does not resolve to the expected digest")
+	ErrTagUnpublished = errors.New("tagged image is not published")
+)
+
+// VerifyTag checks that registry/repository:tag resolves to digest (manifest list or manifest digest)
+// and that the image is published. Errors wrap ErrTagNotFound, ErrTagMismatch or ErrTagUnpublished.
+func VerifyTag(registry, repository, tag, digest string) error {
+	return DefaultClient().VerifyTag(registry, repository, tag, digest)
+}
+
+// VerifyTag is the Client variant of the package-level VerifyTag.
+func (c *Client) VerifyTag(registry, repository, tag, digest string) error {
+	images, err := c.FetchImagesByTag(registry, repository, tag)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	reference := registry + "/" + repository + ":" + tag
+	if len(images) == 0 {
+		return fmt.Errorf("%w: %s", ErrTagNotFound, reference)
+	}
+	var found []string
+	for _, img := range images {
+		for _, repo := range img.Repositories {
+			if repo.Repository != repository {
+				continue
+			}
+			if !strings.EqualFold(repo.ManifestListDigest, digest) && !strings.EqualFold(repo.ManifestSchema2Digest, digest) {
+				if !slices.Contains(found, repo.Digest()) {
+					found = append(found, repo.Digest())
+				}
+				continue
+			}
+			if !repo.Published {
+				return fmt.Errorf("%w: %s (%s)", ErrTagUnpublished, reference, digest)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s resolves to %v, expected %s", ErrTagMismatch, reference, found, digest)
+}
+
+// FindRepositories returns the sorted repositories of registry the digest (manifest list or manifest digest) is
+// published to.
+func FindRepositories(registry, digest string) ([]string, error) {
+	return DefaultClient().FindRepositories(registry, digest)
+}
+
+// FindRepositories is the Client variant of the package-level FindRepositories.
+func (c *Client) FindRepositories(registry, digest string) ([]string, error) {
+	images, err := c.FetchImagesByDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	var repositories []string
+	for _, img := range images {
+		for _, repo := range img.Repositories {
+			if repo.Registry != registry || slices.Contains(repositories, repo.Repository) {
+				continue
+			}
+			if strings.EqualFold(repo.ManifestListDigest, digest) || strings.EqualFold(repo.ManifestSchema2Digest, digest) {
+				repositories = append(repositories, repo.Repository)
+			}
+		}
+	}
+	slices.Sort(repositories)
+	return repositories, nil
+}
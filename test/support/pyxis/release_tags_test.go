@@ -0,0 +1,49 @@
+package pyxis_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/securesign/structural-tests/test/support/pyxis"
+	"github.com/securesign/structural-tests/test/support/pyxis/pyxistest"
+)
+
+var _ = Describe("Release tags", func() {
+	const (
+		registry   = "registry.access.redhat.com"
+		repository = "rhel9/nginx-124"
+	)
+	var client *pyxis.Client
+
+	BeforeEach(func() {
+		server := pyxistest.NewServer(pyxistest.DefaultFixtureDir())
+		DeferCleanup(server.Close)
+		client = pyxis.NewClient(server.URL)
+	})
+
+	It("accepts a tag resolving to the expected digest", func() {
+		Expect(client.VerifyTag(registry, repository, "1", "sha256:"+strings.Repeat("c", 64))).To(Succeed())
+	})
+
+	It("reports a tag resolving to another digest", func() {
+		err := client.VerifyTag(registry, repository, "1", "sha256:"+strings.Repeat("b", 64))
+		Expect(err).To(MatchError(pyxis.ErrTagMismatch))
+		Expect(err).To(MatchError(ContainSubstring("sha256:cccc")))
+	})
+
+	It("reports a missing tag", func() {
+		Expect(client.VerifyTag(registry, repository, "2", "sha256:"+strings.Repeat("c", 64))).To(MatchError(pyxis.ErrTagNotFound))
+	})
+
+	It("reports a tag resolving to an unpublished image", func() {
+		err := client.VerifyTag(registry, "rhtas/rekor-server-rhel9", "1.2", "sha256:"+strings.Repeat("d", 64))
+		Expect(err).To(MatchError(pyxis.ErrTagUnpublished))
+	})
+
+	It("finds the repositories a digest is published to", func() {
+		Expect(client.FindRepositories(registry, "sha256:"+strings.Repeat("a", 64))).To(Equal([]string{"openshift4/ose-cli"}))
+		Expect(client.FindRepositories("registry.example.com", "sha256:"+strings.Repeat("a", 64))).To(BeEmpty())
+		Expect(client.FindRepositories(registry, "sha256:"+strings.Repeat("f", 64))).To(BeEmpty())
+	})
+})
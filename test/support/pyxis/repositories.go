@@ -0,0 +1,75 @@
+package pyxis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"strconv"
+)
+
+const (
+	// DefaultProductListingID is the Red Hat Trusted Artifact Signer product listing.
+	DefaultProductListingID = "6604180e80e2fa3e4947d1d5"
+	// DefaultRepositoryFilter keeps repositories released as Generally Available.
+	DefaultRepositoryFilter = `release_categories=in=("Generally Available")`
+)
+
+// Repository is a product listing repository, in the format of testdata/repositories.json.
+type Repository struct {
+	Name              string   `json:"repository"`
+	ID                string   `json:"_id"` //nolint:tagliatelle // Pyxis API uses _id
+	Published         bool     `json:"published"`
+	ReleaseCategories []string `json:"release_categories,omitempty"` //nolint:tagliatelle // Pyxis API uses snake_case
+}
+
+// RepositoryList is the content of testdata/repositories.json.
+type RepositoryList struct {
+	Data []Repository `json:"data"`
+}
+
+type repositoriesPage struct {
+	Data     []Repository `json:"data"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"` //nolint:tagliatelle // Pyxis API uses snake_case
+	Total    int          `json:"total"`
+}
+
+// FetchProductListingRepositories fetches all repositories of a product listing, following pagination.
+// filter is a Pyxis RSQL filter (e.g. DefaultRepositoryFilter); empty means no filter.
+func (c *Client) FetchProductListingRepositories(ctx context.Context, productListingID, filter string) (*RepositoryList, error) {
+	path := "/product-listings/id/" + url.PathEscape(productListingID) + "/repositories"
+	list := &RepositoryList{}
+	for page := 0; ; page++ {
+		query := url.Values{}
+		if filter != "" {
+			query.Set("filter", filter)
+		}
+		query.Set("include", "data.repository,data._id,data.published,data.release_categories,page,page_size,total")
+		query.Set("page_size", c.pageSize())
+		query.Set("page", strconv.Itoa(page))
+
+		var result repositoriesPage
+		if err := c.getJSON(ctx, path, query, &result); err != nil {
+			return nil, fmt.Errorf("fetch product listing %s repositories: %w", productListingID, err)
+		}
+		list.Data = append(list.Data, result.Data...)
+		if len(result.Data) == 0 || len(list.Data) >= result.Total {
+			break
+		}
+	}
+	log.Printf("Fetched %d repositories of product listing %s\n", len(list.Data), productListingID)
+	return list, nil
+}
+
+// WriteRepositoryList writes the list as indented JSON, the format of testdata/repositories.json.
+func WriteRepositoryList(writer io.Writer, list *RepositoryList) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(list); err != nil {
+		return fmt.Errorf("write repository list: %w", err)
+	}
+	return nil
+}
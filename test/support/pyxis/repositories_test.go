@@ -0,0 +1,52 @@
+package pyxis_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/securesign/structural-tests/test/support/pyxis"
+	"github.com/securesign/structural-tests/test/support/pyxis/pyxistest"
+)
+
+var _ = Describe("Product listing repositories", func() {
+	var client *pyxis.Client
+
+	BeforeEach(func() {
+		server := pyxistest.NewServer(pyxistest.DefaultFixtureDir())
+		DeferCleanup(server.Close)
+		client = pyxis.NewClient(server.URL)
+		client.PageSize = 2
+	})
+
+	It("follows pagination and applies the release category filter", func() {
+		list, err := client.FetchProductListingRepositories(context.Background(), pyxis.DefaultProductListingID, pyxis.DefaultRepositoryFilter)
+		Expect(err).NotTo(HaveOccurred())
+		names := make([]string, 0, len(list.Data))
+		for _, repository := range list.Data {
+			names = append(names, repository.Name)
+		}
+		Expect(names).To(ConsistOf(
+			"rhtas/rhtas-operator-bundle",
+			"rhtas/fulcio-rhel9",
+			"rhtas/rekor-server-rhel9",
+			"rhtas/rekor-monitor-rhel9",
+		))
+	})
+
+	It("writes the repositories.json format", func() {
+		list, err := client.FetchProductListingRepositories(context.Background(), pyxis.DefaultProductListingID, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Data).To(HaveLen(6))
+
+		var buffer bytes.Buffer
+		Expect(pyxis.WriteRepositoryList(&buffer, list)).To(Succeed())
+		var decoded map[string][]map[string]interface{}
+		Expect(json.Unmarshal(buffer.Bytes(), &decoded)).To(Succeed())
+		Expect(decoded["data"][0]).To(HaveKeyWithValue("repository", "rhtas/rhtas-operator-bundle"))
+		Expect(decoded["data"][0]).To(HaveKeyWithValue("_id", "65f19cf311413ce404dadee8"))
+		Expect(decoded["data"][0]).To(HaveKeyWithValue("published", true))
+	})
+})
@@ -0,0 +1,203 @@
+package pyxis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pyxis vulnerability severities.
+const (
+	SeverityCritical  = "Critical"
+	SeverityImportant = "Important"
+	SeverityModerate  = "Moderate"
+	SeverityLow       = "Low"
+)
+
+// Vulnerability is a CVE affecting an image, from the Pyxis image vulnerabilities endpoint.
+type Vulnerability struct {
+	CVEID        string `json:"cve_id"` //nolint:tagliatelle // Pyxis API uses snake_case
+	Severity     string `json:"severity"`
+	AdvisoryID   string `json:"advisory_id"`   //nolint:tagliatelle // Pyxis API uses snake_case
+	AdvisoryType string `json:"advisory_type"` //nolint:tagliatelle // Pyxis API uses snake_case
+	PublicDate   string `json:"public_date"`   //nolint:tagliatelle // Pyxis API uses snake_case
+}
+
+// Fixed reports whether an errata fixing the CVE is available.
+func (v Vulnerability) Fixed() bool {
+	return v.AdvisoryID != ""
+}
+
+// ImageVulnerabilities holds the vulnerabilities of one image architecture.
+type ImageVulnerabilities struct {
+	// Repository is "registry/repository", the key of GradeResults.Grades.
+	Repository      string
+	Architecture    string
+	ImageID         string
+	Vulnerabilities []Vulnerability
+}
+
+type vulnerabilitiesPage struct {
+	Data  []Vulnerability `json:"data"`
+	Total int             `json:"total"`
+}
+
+// FetchImageVulnerabilities returns all vulnerabilities of a Pyxis image (ImageGradeInfo.ID), following pagination.
+func (c *Client) FetchImageVulnerabilities(imageID string) ([]Vulnerability, error) {
+	apiPath := "/images/id/" + url.PathEscape(imageID) + "/vulnerabilities"
+	var vulnerabilities []Vulnerability
+	for page := 0; ; page++ {
+		query := url.Values{}
+		query.Set("include", "data.cve_id,data.severity,data.advisory_id,data.advisory_type,data.public_date,total")
+		query.Set("page_size", c.pageSize())
+		query.Set("page", strconv.Itoa(page))
+
+		var result vulnerabilitiesPage
+		if err := c.getJSON(context.Background(), apiPath, query, &result); err != nil {
+			return nil, fmt.Errorf("fetch vulnerabilities of image %s: %w", imageID, err)
+		}
+		vulnerabilities = append(vulnerabilities, result.Data...)
+		if len(result.Data) == 0 || len(vulnerabilities) >= result.Total {
+			return vulnerabilities, nil
+		}
+	}
+}
+
+// FetchVulnerabilitiesForImages fetches vulnerabilities of every image architecture found by FetchGradesForImages.
+func FetchVulnerabilitiesForImages(gradeResults map[string][]ImageGradeInfo) ([]ImageVulnerabilities, error) {
+	return DefaultClient().FetchVulnerabilitiesForImages(gradeResults)
+}
+
+// FetchVulnerabilitiesForImages is the Client variant of the package-level FetchVulnerabilitiesForImages.
+func (c *Client) FetchVulnerabilitiesForImages(gradeResults map[string][]ImageGradeInfo) ([]ImageVulnerabilities, error) {
+	var results []ImageVulnerabilities
+	for _, repo := range slices.Sorted(maps.Keys(gradeResults)) {
+		for _, img := range gradeResults[repo] {
+			vulnerabilities, err := c.FetchImageVulnerabilities(img.ID)
+			if err != nil {
+				return nil, fmt.Errorf("%s (arch=%s): %w", repo, img.Architecture, err)
+			}
+			log.Printf("Fetched %d vulnerabilities for %s (arch=%s)\n", len(vulnerabilities), repo, img.Architecture)
+			results = append(results, ImageVulnerabilities{
+				Repository:      repo,
+				Architecture:    img.Architecture,
+				ImageID:         img.ID,
+				Vulnerabilities: vulnerabilities,
+			})
+		}
+	}
+	return results, nil
+}
+
+// VulnerabilityPolicy decides which CVEs fail the check, configured by the vulnerabilities section of the suite config:
+//
+//	vulnerabilities:
+//	  failSeverities: [Critical, Important]
+//	  waivers:
+//	    - cve: CVE-2024-0001
+//	      image: registry.redhat.io/openshift4/ose-cli
+//	      expires: "2025-12-31"
+//	      reason: not reachable in the CLI
+type VulnerabilityPolicy struct {
+	// FailSeverities are the severities failing the check when a fix is available.
+	FailSeverities []string `yaml:"failSeverities"`
+	// Waivers accept individual CVEs.
+	Waivers []VulnerabilityWaiver `yaml:"waivers"`
+}
+
+// VulnerabilityWaiver accepts a CVE, for all images or an image ("registry/repository", path.Match patterns allowed).
+type VulnerabilityWaiver struct {
+	CVE   string `yaml:"cve"`
+	Image string `yaml:"image"`
+	// Expires (YYYY-MM-DD) is the last day the waiver applies, empty means it never expires.
+	Expires string `yaml:"expires"`
+	Reason  string `yaml:"reason"`
+}
+
+// DefaultVulnerabilityPolicy fails on fixable Critical and Important CVEs.
+func DefaultVulnerabilityPolicy() VulnerabilityPolicy {
+	return VulnerabilityPolicy{FailSeverities: []string{SeverityCritical, SeverityImportant}}
+}
+
+// Validate checks waivers have a CVE and a parseable expiry date.
+func (p VulnerabilityPolicy) Validate() error {
+	for _, waiver := range p.Waivers {
+		if waiver.CVE == "" {
+			return errors.New("vulnerabilities.waivers entry without cve")
+		}
+		if _, err := path.Match(waiver.Image, ""); err != nil {
+			return fmt.Errorf("vulnerabilities.waivers %s image %q: %w", waiver.CVE, waiver.Image, err)
+		}
+		if waiver.Expires != "" {
+			if _, err := time.Parse(expiresLayout, waiver.Expires); err != nil {
+				return fmt.Errorf("vulnerabilities.waivers %s: invalid expires %q, expected YYYY-MM-DD", waiver.CVE, waiver.Expires)
+			}
+		}
+	}
+	return nil
+}
+
+func (p VulnerabilityPolicy) waived(repo, cve string, now time.Time) bool {
+	for _, waiver := range p.Waivers {
+		if waiver.CVE != cve {
+			continue
+		}
+		if waiver.Image != "" {
+			if matched, err := path.Match(waiver.Image, repo); err != nil || !matched {
+				continue
+			}
+		}
+		if isExpired(waiver.Expires, now) {
+			log.Printf("Waiver of %s for %s expired on %s\n", cve, repo, waiver.Expires)
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// ValidateVulnerabilities reports fixable CVEs with a failing severity that are not waived.
+func ValidateVulnerabilities(results []ImageVulnerabilities, policy VulnerabilityPolicy) []error {
+	now := time.Now().UTC()
+	var errs []error
+	for _, result := range results {
+		for _, vulnerability := range result.Vulnerabilities {
+			if !vulnerability.Fixed() || !slices.ContainsFunc(policy.FailSeverities, func(severity string) bool {
+				return strings.EqualFold(severity, vulnerability.Severity)
+			}) {
+				continue
+			}
+			if policy.waived(result.Repository, vulnerability.CVEID, now) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("%s (arch=%s): %s %s is fixed by %s",
+				result.Repository, result.Architecture, vulnerability.Severity, vulnerability.CVEID, vulnerability.AdvisoryID))
+		}
+	}
+	return errs
+}
+
+// SummarizeVulnerabilities lists CVE counts by severity for every image architecture, one per line.
+func SummarizeVulnerabilities(results []ImageVulnerabilities) string {
+	var builder strings.Builder
+	for _, result := range results {
+		counts := make(map[string]int)
+		for _, vulnerability := range result.Vulnerabilities {
+			counts[vulnerability.Severity]++
+		}
+		fmt.Fprintf(&builder, "  %s (arch=%s):", result.Repository, result.Architecture)
+		for _, severity := range []string{SeverityCritical, SeverityImportant, SeverityModerate, SeverityLow} {
+			fmt.Fprintf(&builder, " %s=%d", severity, counts[severity])
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
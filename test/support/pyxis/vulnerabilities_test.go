@@ -0,0 +1,57 @@
+package pyxis_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/securesign/structural-tests/test/support/pyxis"
+	"github.com/securesign/structural-tests/test/support/pyxis/pyxistest"
+)
+
+var _ = Describe("Vulnerabilities", func() {
+	var (
+		client  *pyxis.Client
+		results []pyxis.ImageVulnerabilities
+	)
+
+	BeforeEach(func() {
+		server := pyxistest.NewServer(pyxistest.DefaultFixtureDir())
+		DeferCleanup(server.Close)
+		client = pyxis.NewClient(server.URL)
+		client.PageSize = 2
+
+		grades, err := client.FetchGradesForImages(map[string]string{"nginx-image": gradeCImage, "ose-cli-image": gradeAImage})
+		Expect(err).NotTo(HaveOccurred())
+		results, err = client.FetchVulnerabilitiesForImages(grades.Grades)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("fetches vulnerabilities of every architecture across pages", func() {
+		Expect(results).To(HaveLen(4))
+		Expect(results[2].Repository).To(Equal("registry.redhat.io/rhel9/nginx-124"))
+		Expect(results[3].Architecture).To(Equal("arm64"))
+		Expect(results[3].Vulnerabilities).To(HaveLen(3))
+		Expect(pyxis.SummarizeVulnerabilities(results)).To(ContainSubstring(
+			"registry.redhat.io/rhel9/nginx-124 (arch=arm64): Critical=1 Important=1 Moderate=1 Low=0"))
+	})
+
+	It("fails only on fixable CVEs with a failing severity", func() {
+		errs := pyxis.ValidateVulnerabilities(results, pyxis.DefaultVulnerabilityPolicy())
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0]).To(MatchError("registry.redhat.io/rhel9/nginx-124 (arch=arm64): Important CVE-2024-1001 is fixed by RHSA-2024:1001"))
+	})
+
+	It("honors waivers until they expire", func() {
+		policy := pyxis.DefaultVulnerabilityPolicy()
+		policy.Waivers = []pyxis.VulnerabilityWaiver{{CVE: "CVE-2024-1001", Image: "registry.redhat.io/rhel9/*"}}
+		Expect(policy.Validate()).To(Succeed())
+		Expect(pyxis.ValidateVulnerabilities(results, policy)).To(BeEmpty())
+
+		policy.Waivers[0].Expires = time.Now().AddDate(0, 0, -1).Format(time.DateOnly)
+		Expect(pyxis.ValidateVulnerabilities(results, policy)).To(HaveLen(1))
+
+		policy.Waivers = []pyxis.VulnerabilityWaiver{{CVE: "CVE-2024-1001", Image: "registry.redhat.io/openshift4/*"}}
+		Expect(pyxis.ValidateVulnerabilities(results, policy)).To(HaveLen(1))
+	})
+})
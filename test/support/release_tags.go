@@ -0,0 +1,109 @@
+package support
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/securesign/structural-tests/test/support/pyxis"
+)
+
+const defaultPyxisRegistry = "registry.access.redhat.com"
+
+// ExpandReleaseTags replaces {version}, {minor} and {major} in tag templates with parts of version (e.g. 1.2.1).
+func ExpandReleaseTags(templates []string, version string) []string {
+	minor, major := versionParts(version)
+	replacer := strings.NewReplacer("{version}", version, "{minor}", minor, "{major}", major)
+	tags := make([]string, 0, len(templates))
+	for _, template := range templates {
+		if tag := replacer.Replace(template); !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// versionParts returns the major.minor and major parts of version.
+func versionParts(version string) (string, string) {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3) //nolint:mnd // major.minor.patch
+	major := parts[0]
+	minor := major
+	if len(parts) > 1 {
+		minor = parts[0] + "." + parts[1]
+	}
+	return minor, major
+}
+
+// ErrNoReleasedRepository is returned for snapshot images not published to any repository of the repository list.
+var ErrNoReleasedRepository = errors.New("no released repository found")
+
+// ReleasedSnapshotImages maps snapshot images to the registry.redhat.io repositories of the list their digest is
+// published to in Pyxis, keeping the snapshot digest. Keys of repositories.unreleased are skipped, any other key
+// without a released repository is returned as an error wrapping ErrNoReleasedRepository. Images released to more
+// than one repository are mapped as "key (repository)".
+func ReleasedSnapshotImages(snapshotData SnapshotData, repositories *RepositoryList, policy RepositoryPolicy) (map[string]string, []error) {
+	released := make(map[string]string)
+	var errs []error
+	for _, key := range GetMapKeysSorted(snapshotData.Images) {
+		if matchesAnyPattern(policy.Unreleased, key) {
+			continue
+		}
+		digest := "sha256:" + ExtractHash(snapshotData.Images[key])
+		published, err := pyxis.FindRepositories(policy.pyxisRegistry(), digest)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		var listed []string
+		for _, repository := range published {
+			if repositories.FindByImage("registry.redhat.io/"+repository+"@"+digest) != nil {
+				listed = append(listed, repository)
+			}
+		}
+		switch len(listed) {
+		case 0:
+			errs = append(errs, fmt.Errorf("%s: %w for %s (published to %v)", key, ErrNoReleasedRepository, digest, published))
+		case 1:
+			released[key] = "registry.redhat.io/" + listed[0] + "@" + digest
+		default:
+			for _, repository := range listed {
+				released[key+" ("+repository+")"] = "registry.redhat.io/" + repository + "@" + digest
+			}
+		}
+	}
+	return released, errs
+}
+
+// VerifyReleaseTags checks that every release tag of the registry.redhat.io images resolves in Pyxis
+// to the image digest and is published.
+func VerifyReleaseTags(images map[string]string, policy RepositoryPolicy, version string) []error {
+	if version == "" {
+		return []error{errors.New("VERSION is required to verify release tags")}
+	}
+	registry := policy.pyxisRegistry()
+	tags := ExpandReleaseTags(policy.ReleaseTags, version)
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(images)) {
+		match := containerRegexp.FindStringSubmatch(images[key])
+		if match == nil {
+			errs = append(errs, fmt.Errorf("%s: cannot parse image %s", key, images[key]))
+			continue
+		}
+		digest := "sha256:" + ExtractHash(images[key])
+		for _, tag := range tags {
+			if err := pyxis.VerifyTag(registry, match[groupImage], tag, digest); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+	}
+	return errs
+}
+
+func (p RepositoryPolicy) pyxisRegistry() string {
+	if p.PyxisRegistry == "" {
+		return defaultPyxisRegistry
+	}
+	return p.PyxisRegistry
+}
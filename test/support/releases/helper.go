@@ -0,0 +1,213 @@
+package releases
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:stylecheck
+	. "github.com/onsi/gomega"    //nolint:stylecheck
+	"github.com/securesign/structural-tests/test/support"
+)
+
+// DescribeSnapshotImageTests registers the checks of the snapshot.json images of a product. embeddedDefaults are the
+// defaults.yaml of the suite, merged with TEST_CONFIG before the specs run.
+//
+//nolint:funlen
+func DescribeSnapshotImageTests(product string, embeddedDefaults []byte) bool {
+	return Describe("Snapshot images", Ordered, func() {
+		var (
+			defaultsData []byte
+			snapshotData support.SnapshotData
+			rpmInventory support.RPMInventory
+		)
+
+		BeforeAll(func() {
+			var err error
+			defaultsData, err = support.SuiteDefaults(embeddedDefaults)
+			Expect(err).NotTo(HaveOccurred(), "failed to load the suite config of %s", product)
+		})
+
+		It("snapshot.json file exist and is parseable", func() {
+			var err error
+			snapshotData, err = support.ParseSnapshotData()
+			Expect(err).NotTo(HaveOccurred())
+			support.LogMap(fmt.Sprintf("Snapshot images (%d):", len(snapshotData.Images)), snapshotData.Images)
+			Expect(snapshotData.Images).NotTo(BeEmpty(), "No images were detected in snapshot file")
+		})
+
+		It("snapshot.json file contains valid images", func() {
+			Expect(snapshotData.Images).To(HaveEach(MatchRegexp(support.SnapshotImageDefinitionRegexp)))
+		})
+
+		It("snapshot.json file image snapshots are all unique", func() {
+			snapshotHashes := support.ExtractHashes(support.GetMapValues(snapshotData.Images))
+			mapped := make(map[string]int)
+			for _, hash := range snapshotHashes {
+				_, exist := mapped[hash]
+				if exist {
+					mapped[hash]++
+				} else {
+					mapped[hash] = 1
+				}
+			}
+			Expect(mapped).To(HaveEach(1))
+			Expect(len(snapshotData.Images)).To(BeNumerically("==", len(mapped)))
+		})
+
+		if support.IsPostGA() {
+			It("snapshot.json images are published with release tags", func() {
+				policy, err := support.GetRepositoryPolicyFromConfig(defaultsData)
+				Expect(err).NotTo(HaveOccurred())
+				if len(policy.ReleaseTags) == 0 {
+					Skip("no repositories.releaseTags configured for " + product)
+				}
+				repositories, err := support.LoadRepositoryList()
+				Expect(err).NotTo(HaveOccurred())
+				released, errs := support.ReleasedSnapshotImages(snapshotData, repositories, policy)
+				Expect(errs).To(BeEmpty(), "Some snapshot images are not released to a repository of the repository list")
+				support.LogMap(fmt.Sprintf("Released snapshot images (%d):", len(released)), released)
+				errs = support.VerifyReleaseTags(released, policy, support.GetVersion())
+				Expect(errs).To(BeEmpty(), "Some snapshot images are not published with release tags")
+			})
+		}
+
+		It("snapshot.json images are built for all expected platforms", func() {
+			policy, err := support.GetPlatformPolicyFromConfig(defaultsData)
+			Expect(err).NotTo(HaveOccurred())
+			checks, err := support.CheckSnapshotPlatforms(context.Background(), snapshotData.Images, policy)
+			Expect(err).NotTo(HaveOccurred())
+			matrix := support.FormatPlatformMatrix(checks)
+			log.Printf("Snapshot image platforms:\n%s", matrix)
+			for _, check := range checks {
+				if !check.OK() {
+					Fail("Snapshot images with missing or extra platforms:\n" + matrix)
+				}
+			}
+		})
+
+		It("snapshot.json images have correct labels", func() {
+			policy, err := support.GetLabelPolicyFromConfig(defaultsData)
+			Expect(err).NotTo(HaveOccurred())
+			problems, err := support.CheckSnapshotLabels(context.Background(), snapshotData.Images, policy)
+			Expect(err).NotTo(HaveOccurred())
+			if len(problems) > 0 {
+				Fail("Label validation errors found:\n" + support.FormatLabelReport(snapshotData.Images, problems, policy))
+			}
+		})
+
+		It("snapshot.json images have version labels matching VERSION", func() {
+			policy, err := support.GetVersionPolicyFromConfig(defaultsData)
+			Expect(err).NotTo(HaveOccurred())
+			deviations, skipped, err := support.CheckSnapshotVersions(context.Background(), snapshotData.Images, policy, support.GetVersion())
+			Expect(err).NotTo(HaveOccurred())
+			if len(skipped) > 0 {
+				support.LogMap("Images excluded from the version check:", skipped)
+			}
+			if len(deviations) > 0 {
+				Fail("Version label deviations found:\n" + support.FormatVersionDeviations(deviations))
+			}
+		})
+
+		It("snapshot.json images share source revisions within provenance groups", func() {
+			policy, err := support.GetProvenancePolicyFromConfig(defaultsData)
+			Expect(err).NotTo(HaveOccurred())
+			if len(policy.Groups) == 0 {
+				Skip("no provenance.groups configured")
+			}
+			checks, err := support.CheckProvenance(snapshotData.Images, policy)
+			Expect(err).NotTo(HaveOccurred())
+			report := support.FormatProvenanceReport(checks)
+			log.Printf("Snapshot image provenance:\n%s", report)
+			for _, check := range checks {
+				if len(check.Problems) > 0 {
+					Fail("Provenance problems found:\n" + report)
+				}
+			}
+		})
+
+		It("snapshot.json images have fresh and ordered build dates", func() {
+			policy, err := support.GetBuildDatePolicyFromConfig(defaultsData)
+			Expect(err).NotTo(HaveOccurred())
+			provenance, err := support.GetProvenancePolicyFromConfig(defaultsData)
+			Expect(err).NotTo(HaveOccurred())
+			builds, problems, err := support.CollectImageBuilds(snapshotData.Images)
+			Expect(err).NotTo(HaveOccurred())
+			log.Printf("Snapshot images by build date:\n%s", support.FormatBuildTimeline(builds))
+			if rebuilds := policy.FindRebuilds(builds, provenance.Groups); len(rebuilds) > 0 {
+				support.LogArray("Images rebuilt from the same vcs-ref:", rebuilds)
+			}
+			problems = append(problems, policy.CheckBuildDates(builds)...)
+			Expect(problems).To(BeEmpty(), "Some snapshot images have stale or out-of-order build dates")
+		})
+
+		It("snapshot.json vcs-ref commits are on the release branch of the git mirrors", func() {
+			mirrors := support.GetEnv(support.EnvGitMirrors)
+			if mirrors == "" {
+				Skip(support.EnvGitMirrors + " is not set")
+			}
+			policy, err := support.GetGitMirrorPolicyFromConfig(defaultsData)
+			Expect(err).NotTo(HaveOccurred())
+			checks, skipped, err := support.VerifyMirrorCommits(context.Background(), mirrors, snapshotData.Images, policy, support.GetVersion())
+			Expect(err).NotTo(HaveOccurred())
+			if len(skipped) > 0 {
+				support.LogMap("Images without git mirror check:", skipped)
+			}
+			report := support.FormatCommitChecks(checks)
+			log.Printf("Snapshot image commits:\n%s", report)
+			for _, check := range checks {
+				if check.Err != nil {
+					Fail("Some vcs-ref commits are missing or not on the release branch:\n" + report)
+				}
+			}
+		})
+
+		It("snapshot.json service images are hardened", func() {
+			policy, err := support.GetHardeningPolicyFromConfig(defaultsData)
+			Expect(err).NotTo(HaveOccurred())
+			problems, err := support.CheckSnapshotHardening(context.Background(), snapshotData.Images, policy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(BeEmpty(), "Some service images are not hardened")
+		})
+
+		It("snapshot.json images contain only allowed RPMs", func() {
+			policy, err := support.GetRPMPolicyFromConfig(defaultsData)
+			Expect(err).NotTo(HaveOccurred())
+			var missing []string
+			rpmInventory, missing, err = support.CollectRPMInventory(context.Background(), snapshotData.Images, policy)
+			Expect(err).NotTo(HaveOccurred())
+			log.Printf("Snapshot image RPMs:\n%s", support.FormatRPMInventory(rpmInventory))
+			if inventoryFile := support.GetEnv(support.EnvRPMInventory); inventoryFile != "" {
+				Expect(support.WriteRPMInventory(inventoryFile, rpmInventory)).To(Succeed())
+			}
+			Expect(missing).To(BeEmpty(), "Some images have no RPM database")
+			Expect(policy.CheckInventory(rpmInventory)).To(BeEmpty(), "Some images contain denied RPMs")
+		})
+
+		It("snapshot.json RPM changes against the baseline snapshot are reported", func() {
+			baselineFile := support.GetEnv(support.EnvRPMBaselineSnapshot)
+			if baselineFile == "" || rpmInventory == nil {
+				Skip(support.EnvRPMBaselineSnapshot + " is not set or no RPM inventory was collected")
+			}
+			baseline, err := support.ParseSnapshotFile(baselineFile)
+			Expect(err).NotTo(HaveOccurred())
+			changes, err := support.DiffRPMBaseline(context.Background(), baseline.Images, rpmInventory)
+			Expect(err).NotTo(HaveOccurred())
+			log.Printf("RPM changes against %s (%s):\n%s", baselineFile, support.DefaultPlatform, support.FormatRPMChanges(changes))
+		})
+
+		It("snapshot.json images contain content manifests and licenses", func() {
+			policy, err := support.GetContentManifestPolicyFromConfig(defaultsData)
+			Expect(err).NotTo(HaveOccurred())
+			checks, err := support.CheckSnapshotContent(context.Background(), snapshotData.Images, policy)
+			Expect(err).NotTo(HaveOccurred())
+			report := support.FormatContentReport(checks)
+			log.Printf("Snapshot image content manifests and licenses:\n%s", report)
+			for _, check := range checks {
+				if len(check.Problems) > 0 {
+					Fail("Snapshot images with invalid content manifests or licenses:\n" + report)
+				}
+			}
+		})
+	})
+}
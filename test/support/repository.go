@@ -1,10 +1,16 @@
 package support
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/securesign/structural-tests/test/support/pyxis"
 )
 
 const (
@@ -16,9 +22,35 @@ const (
 var containerRegexp = regexp.MustCompile(`^(?P<registry>[\w.\-_]+)/(?P<image>[^@:]+)(?P<tag>.+)$`)
 
 type Repository struct {
-	Name      string `json:"repository"`
-	ID        string `json:"_id"` //nolint:tagliatelle
-	Published bool   `json:"published"`
+	Name              string   `json:"repository"`
+	ID                string   `json:"_id"` //nolint:tagliatelle
+	Published         bool     `json:"published"`
+	ReleaseCategories []string `json:"release_categories,omitempty"` //nolint:tagliatelle
+}
+
+var (
+	ErrRepositoryMissing       = errors.New("not found in registry")
+	ErrRepositoryUnpublished   = errors.New("repository is not published")
+	ErrRepositoryWrongCategory = errors.New("repository is published in a wrong release category")
+)
+
+// RepositoryPolicy describes which repository states are acceptable (repositories section of the suite config).
+type RepositoryPolicy struct {
+	// ReleaseCategories lists accepted release categories. Empty accepts any; when set, repositories
+	// without release category data are reported as in a wrong category.
+	ReleaseCategories []string `yaml:"releaseCategories"`
+	// AllowedUnpublished lists repositories expected to be unpublished before GA.
+	AllowedUnpublished []string `yaml:"allowedUnpublished"`
+	// PostGA requires every repository to be published, ignoring AllowedUnpublished.
+	PostGA bool `yaml:"postGA"`
+	// Unreleased are snapshot image keys (path.Match patterns) not released to registry.redhat.io by digest, e.g. FBC images.
+	Unreleased []string `yaml:"unreleased"`
+	// ReleaseTags are the tags of a released image ({version}, {minor} and {major} of VERSION are replaced).
+	ReleaseTags []string `yaml:"releaseTags"`
+	// PyxisRegistry is the registry name of registry.redhat.io repositories in Pyxis.
+	PyxisRegistry string `yaml:"pyxisRegistry"`
+	// Orphans configures the report of repositories not used by any image.
+	Orphans OrphanPolicy `yaml:"orphans"`
 }
 
 type RepositoryList struct {
@@ -39,12 +71,42 @@ func (r *RepositoryList) FindByImage(image string) *Repository {
 	return nil
 }
 
+// CheckImage returns nil when the image repository is listed and acceptable for the policy. Otherwise
+// the error wraps ErrRepositoryMissing, ErrRepositoryUnpublished or ErrRepositoryWrongCategory.
+func (r *RepositoryList) CheckImage(image string, policy RepositoryPolicy) error {
+	repository := r.FindByImage(image)
+	if repository == nil {
+		return fmt.Errorf("%w: %s", ErrRepositoryMissing, image)
+	}
+	if !repository.Published {
+		if policy.PostGA {
+			return fmt.Errorf("%w (post-GA): %s: %s", ErrRepositoryUnpublished, repository.Name, image)
+		}
+		if !slices.Contains(policy.AllowedUnpublished, repository.Name) {
+			return fmt.Errorf("%w and not in allowedUnpublished: %s: %s", ErrRepositoryUnpublished, repository.Name, image)
+		}
+	}
+	if len(policy.ReleaseCategories) > 0 && !slices.ContainsFunc(repository.ReleaseCategories, func(category string) bool {
+		return slices.Contains(policy.ReleaseCategories, category)
+	}) {
+		return fmt.Errorf("%w %v, expected one of %v: %s: %s",
+			ErrRepositoryWrongCategory, repository.ReleaseCategories, policy.ReleaseCategories, repository.Name, image)
+	}
+	return nil
+}
+
+// LoadRepositoryList loads the repositories file pointed to by REPOSITORIES (testdata/repositories.json by default).
+// REPOSITORIES=pyxis or pyxis:<product-listing-id> fetches the list on the fly from the Pyxis API at PYXIS_URL instead,
+// with repositories of every release category so that wrong categories are reported as such and not as missing.
 func LoadRepositoryList() (*RepositoryList, error) {
 	fileName := GetEnv(EnvRepositoriesFile)
 	if fileName == "" {
 		fileName = DefaultRepositoriesFile
 		log.Printf("using default repositories file %s\n", DefaultRepositoriesFile)
 	}
+	if fileName == PyxisRepositoriesSource || strings.HasPrefix(fileName, PyxisRepositoriesSource+":") {
+		return loadRepositoryListFromPyxis(strings.TrimPrefix(strings.TrimPrefix(fileName, PyxisRepositoriesSource), ":"))
+	}
 	content, err := GetFileContent(fileName)
 	if err != nil {
 		return nil, err
@@ -56,3 +118,23 @@ func LoadRepositoryList() (*RepositoryList, error) {
 	}
 	return &list, nil
 }
+
+func loadRepositoryListFromPyxis(productListingID string) (*RepositoryList, error) {
+	if productListingID == "" {
+		productListingID = pyxis.DefaultProductListingID
+	}
+	pyxisList, err := pyxis.DefaultClient().FetchProductListingRepositories(context.Background(), productListingID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load repositories from pyxis: %w", err)
+	}
+	list := &RepositoryList{Data: make([]Repository, 0, len(pyxisList.Data))}
+	for _, repository := range pyxisList.Data {
+		list.Data = append(list.Data, Repository{
+			Name:              repository.Name,
+			ID:                repository.ID,
+			Published:         repository.Published,
+			ReleaseCategories: repository.ReleaseCategories,
+		})
+	}
+	return list, nil
+}
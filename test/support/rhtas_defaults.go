@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/securesign/structural-tests/test/support/pyxis"
 	"gopkg.in/yaml.v3"
 )
 
@@ -44,6 +45,20 @@ func toMapAny(input interface{}) (map[string]interface{}, bool) {
 	return nil, false
 }
 
+// SuiteDefaults returns the embedded defaults of a suite merged with the TEST_CONFIG file, if set. Errors reading
+// or merging TEST_CONFIG are returned, never hidden behind the embedded defaults.
+func SuiteDefaults(defaults []byte) ([]byte, error) {
+	content, err := GetTestConfigContent()
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", EnvTestConfig, err)
+	}
+	merged, err := MergeDefaultsConfig(defaults, content)
+	if err != nil {
+		return nil, fmt.Errorf("merge %s: %w", EnvTestConfig, err)
+	}
+	return merged, nil
+}
+
 // MergeDefaultsConfig overlays fileContent on top of baseDefaults. Both may use package wrapper (rhtas:)
 // or suite-level (operator, ansible, fbc). Result is always suite-level. Keys in fileContent override baseDefaults.
 func MergeDefaultsConfig(baseDefaults, fileContent []byte) ([]byte, error) {
@@ -119,3 +134,177 @@ func GetAnsibleImageKeysFromConfig(defaultsYaml []byte) ([]string, []string, err
 	}
 	return parsed.Ansible.ImageKeys, parsed.Ansible.OtherImageKeys, nil
 }
+
+// DecodeSuiteSection decodes a top-level section of the suite config (e.g. "repositories") into target.
+// Returns false when the section is not present.
+func DecodeSuiteSection(defaultsYaml []byte, section string, target interface{}) (bool, error) {
+	if len(defaultsYaml) == 0 {
+		return false, nil
+	}
+	suites, err := SuiteLevelMap(defaultsYaml)
+	if err != nil {
+		return false, err
+	}
+	value, ok := suites[section]
+	if !ok || value == nil {
+		return false, nil
+	}
+	bytes, err := yaml.Marshal(EnsureStringKeys(value))
+	if err != nil {
+		return false, fmt.Errorf("marshal %s section: %w", section, err)
+	}
+	if err := yaml.Unmarshal(bytes, target); err != nil {
+		return false, fmt.Errorf("decode %s section: %w", section, err)
+	}
+	return true, nil
+}
+
+// GetRepositoryPolicyFromConfig returns the repositories section of the config. RELEASE_PHASE=post-ga enables PostGA.
+func GetRepositoryPolicyFromConfig(defaultsYaml []byte) (RepositoryPolicy, error) {
+	var policy RepositoryPolicy
+	if _, err := DecodeSuiteSection(defaultsYaml, "repositories", &policy); err != nil {
+		return RepositoryPolicy{}, err
+	}
+	policy.PostGA = policy.PostGA || IsPostGA()
+	return policy, nil
+}
+
+// GetGradePolicyFromConfig returns the grades section of the config on top of pyxis.DefaultGradePolicy,
+// with asOf replaced by GRADES_AS_OF when it is set.
+func GetGradePolicyFromConfig(defaultsYaml []byte) (pyxis.GradePolicy, error) {
+	policy := pyxis.DefaultGradePolicy()
+	if _, err := DecodeSuiteSection(defaultsYaml, "grades", &policy); err != nil {
+		return pyxis.GradePolicy{}, err
+	}
+	policy = policy.WithEnvAsOf()
+	if err := policy.Validate(); err != nil {
+		return pyxis.GradePolicy{}, fmt.Errorf("invalid grades config: %w", err)
+	}
+	return policy, nil
+}
+
+// GetVulnerabilityPolicyFromConfig returns the vulnerabilities section of the config on top of pyxis.DefaultVulnerabilityPolicy.
+func GetVulnerabilityPolicyFromConfig(defaultsYaml []byte) (pyxis.VulnerabilityPolicy, error) {
+	policy := pyxis.DefaultVulnerabilityPolicy()
+	if _, err := DecodeSuiteSection(defaultsYaml, "vulnerabilities", &policy); err != nil {
+		return pyxis.VulnerabilityPolicy{}, err
+	}
+	if err := policy.Validate(); err != nil {
+		return pyxis.VulnerabilityPolicy{}, fmt.Errorf("invalid vulnerabilities config: %w", err)
+	}
+	return policy, nil
+}
+
+// GetNewerDigestPolicyFromConfig returns the newerDigests section of the config on top of pyxis.DefaultNewerDigestPolicy.
+func GetNewerDigestPolicyFromConfig(defaultsYaml []byte) (pyxis.NewerDigestPolicy, error) {
+	policy := pyxis.DefaultNewerDigestPolicy()
+	if _, err := DecodeSuiteSection(defaultsYaml, "newerDigests", &policy); err != nil {
+		return pyxis.NewerDigestPolicy{}, err
+	}
+	return policy, nil
+}
+
+// GetPlatformPolicyFromConfig returns the platforms section of the config.
+func GetPlatformPolicyFromConfig(defaultsYaml []byte) (PlatformPolicy, error) {
+	var policy PlatformPolicy
+	if _, err := DecodeSuiteSection(defaultsYaml, "platforms", &policy); err != nil {
+		return PlatformPolicy{}, err
+	}
+	return policy, nil
+}
+
+// GetLabelPolicyFromConfig returns the labels section of the config, or DefaultLabelPolicy when there is none.
+func GetLabelPolicyFromConfig(defaultsYaml []byte) (LabelPolicy, error) {
+	var policy LabelPolicy
+	found, err := DecodeSuiteSection(defaultsYaml, "labels", &policy)
+	if err != nil {
+		return LabelPolicy{}, err
+	}
+	if !found {
+		return DefaultLabelPolicy(), nil
+	}
+	if err := policy.Validate(); err != nil {
+		return LabelPolicy{}, fmt.Errorf("invalid labels config: %w", err)
+	}
+	return policy, nil
+}
+
+// GetVersionPolicyFromConfig returns the versions section of the config on top of DefaultVersionPolicy.
+func GetVersionPolicyFromConfig(defaultsYaml []byte) (VersionPolicy, error) {
+	policy := DefaultVersionPolicy()
+	if _, err := DecodeSuiteSection(defaultsYaml, "versions", &policy); err != nil {
+		return VersionPolicy{}, err
+	}
+	if err := policy.Validate(); err != nil {
+		return VersionPolicy{}, fmt.Errorf("invalid versions config: %w", err)
+	}
+	return policy, nil
+}
+
+// GetProvenancePolicyFromConfig returns the provenance section of the config.
+func GetProvenancePolicyFromConfig(defaultsYaml []byte) (ProvenancePolicy, error) {
+	var policy ProvenancePolicy
+	if _, err := DecodeSuiteSection(defaultsYaml, "provenance", &policy); err != nil {
+		return ProvenancePolicy{}, err
+	}
+	if err := policy.Validate(); err != nil {
+		return ProvenancePolicy{}, fmt.Errorf("invalid provenance config: %w", err)
+	}
+	return policy, nil
+}
+
+// GetBuildDatePolicyFromConfig returns the buildDates section of the config on top of DefaultBuildDatePolicy.
+func GetBuildDatePolicyFromConfig(defaultsYaml []byte) (BuildDatePolicy, error) {
+	policy := DefaultBuildDatePolicy()
+	if _, err := DecodeSuiteSection(defaultsYaml, "buildDates", &policy); err != nil {
+		return BuildDatePolicy{}, err
+	}
+	if err := policy.Validate(); err != nil {
+		return BuildDatePolicy{}, fmt.Errorf("invalid buildDates config: %w", err)
+	}
+	return policy, nil
+}
+
+// GetGitMirrorPolicyFromConfig returns the gitMirrors section of the config on top of DefaultGitMirrorPolicy.
+func GetGitMirrorPolicyFromConfig(defaultsYaml []byte) (GitMirrorPolicy, error) {
+	policy := DefaultGitMirrorPolicy()
+	if _, err := DecodeSuiteSection(defaultsYaml, "gitMirrors", &policy); err != nil {
+		return GitMirrorPolicy{}, err
+	}
+	return policy, nil
+}
+
+// GetHardeningPolicyFromConfig returns the hardening section of the config on top of DefaultHardeningPolicy.
+func GetHardeningPolicyFromConfig(defaultsYaml []byte) (HardeningPolicy, error) {
+	policy := DefaultHardeningPolicy()
+	if _, err := DecodeSuiteSection(defaultsYaml, "hardening", &policy); err != nil {
+		return HardeningPolicy{}, err
+	}
+	return policy, nil
+}
+
+// GetRPMPolicyFromConfig returns the rpms section of the config.
+func GetRPMPolicyFromConfig(defaultsYaml []byte) (RPMPolicy, error) {
+	var policy RPMPolicy
+	if _, err := DecodeSuiteSection(defaultsYaml, "rpms", &policy); err != nil {
+		return RPMPolicy{}, err
+	}
+	for index, class := range policy.Classes {
+		if class.Name == "" || len(class.Images) == 0 {
+			return RPMPolicy{}, fmt.Errorf("invalid rpms config: class %d needs a name and images", index)
+		}
+	}
+	return policy, nil
+}
+
+// GetContentManifestPolicyFromConfig returns the contentManifests section of the config on top of DefaultContentManifestPolicy.
+func GetContentManifestPolicyFromConfig(defaultsYaml []byte) (ContentManifestPolicy, error) {
+	policy := DefaultContentManifestPolicy()
+	if _, err := DecodeSuiteSection(defaultsYaml, "contentManifests", &policy); err != nil {
+		return ContentManifestPolicy{}, err
+	}
+	if err := policy.Validate(); err != nil {
+		return ContentManifestPolicy{}, fmt.Errorf("invalid contentManifests config: %w", err)
+	}
+	return policy, nil
+}
@@ -0,0 +1,241 @@
+package support
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/docker/docker/client"
+	"github.com/securesign/structural-tests/test/support/rpmdb"
+)
+
+// ErrNoRPMDatabase is returned for images without an rpmdb.sqlite database.
+var ErrNoRPMDatabase = errors.New("no rpm database found")
+
+// RPMPolicy configures the RPM content check (rpms section of the suite config).
+type RPMPolicy struct {
+	// Exclude are keys (path.Match patterns) of images without an RPM database, e.g. bundles.
+	Exclude []string `yaml:"exclude"`
+	// Classes are checked by the first class matching the image key.
+	Classes []RPMClass `yaml:"classes"`
+}
+
+// RPMClass is a class of images with its own package lists.
+type RPMClass struct {
+	Name string `yaml:"name"`
+	// Images are image keys (path.Match patterns) of the class.
+	Images []string `yaml:"images"`
+	// Allow are package name patterns; when set, any other package is denied.
+	Allow []string `yaml:"allow"`
+	// Deny are package name patterns that must not be installed.
+	Deny []string `yaml:"deny"`
+}
+
+// RPMInventory lists the installed packages by image key and platform.
+type RPMInventory map[string]map[string][]rpmdb.Package
+
+// ClassOf returns the first class matching the image key, nil if none does.
+func (p RPMPolicy) ClassOf(key string) *RPMClass {
+	for index, class := range p.Classes {
+		if matchesAnyPattern(class.Images, key) {
+			return &p.Classes[index]
+		}
+	}
+	return nil
+}
+
+// CheckPackages returns packages of the image key denied by its class.
+func (p RPMPolicy) CheckPackages(key string, packages []rpmdb.Package) []string {
+	class := p.ClassOf(key)
+	if class == nil {
+		return nil
+	}
+	var problems []string
+	for _, pkg := range packages {
+		if pattern := matchingPattern(class.Deny, pkg.Name); pattern != "" {
+			problems = append(problems, fmt.Sprintf("%s: denied by %s (%s)", pkg.NEVRA(), class.Name, pattern))
+		} else if len(class.Allow) > 0 && matchingPattern(class.Allow, pkg.Name) == "" && pkg.Name != "gpg-pubkey" {
+			problems = append(problems, fmt.Sprintf("%s: not allowed by %s", pkg.NEVRA(), class.Name))
+		}
+	}
+	return problems
+}
+
+func matchingPattern(patterns []string, name string) string {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// CheckInventory returns denied packages by image key and platform.
+func (p RPMPolicy) CheckInventory(inventory RPMInventory) map[string]map[string][]string {
+	result := make(map[string]map[string][]string)
+	for key, platforms := range inventory {
+		for platform, packages := range platforms {
+			if problems := p.CheckPackages(key, packages); len(problems) > 0 {
+				if result[key] == nil {
+					result[key] = make(map[string][]string)
+				}
+				result[key][platform] = problems
+			}
+		}
+	}
+	return result
+}
+
+// ReadImagePackages reads the RPM database of the platform (os/arch) variant of an image without running it. Only a
+// missing database path moves on to the next candidate, other errors are returned.
+func ReadImagePackages(ctx context.Context, imageDefinition, platform string) ([]rpmdb.Package, error) {
+	filesystem, err := OpenImageFilesystemOnPlatform(ctx, imageDefinition, platform)
+	if err != nil {
+		return nil, err
+	}
+	defer filesystem.Close(ctx) //nolint:errcheck // best effort cleanup
+	for _, databasePath := range rpmdb.DatabasePaths {
+		reader, err := filesystem.Copy(ctx, databasePath)
+		if client.IsErrNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var database bytes.Buffer
+		err = extractFileFromTar(reader, &database)
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", databasePath, err)
+		}
+		packages, err := rpmdb.Read(database.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", databasePath, err)
+		}
+		return packages, nil
+	}
+	return nil, ErrNoRPMDatabase
+}
+
+// CollectRPMInventory reads the packages of every platform of the images not excluded by the policy.
+// Images without an RPM database are returned as problems.
+func CollectRPMInventory(ctx context.Context, images map[string]string, policy RPMPolicy) (RPMInventory, []string, error) {
+	inventory := make(RPMInventory)
+	var (
+		problems []string
+		errs     []error
+	)
+	for _, key := range GetMapKeysSorted(images) {
+		if matchesAnyPattern(policy.Exclude, key) {
+			continue
+		}
+		platformImages, err := ExpandManifestList(ctx, images[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		for _, platformImage := range platformImages {
+			packages, err := ReadImagePackages(ctx, platformImage.Image, platformImage.Platform)
+			if errors.Is(err, ErrNoRPMDatabase) {
+				problems = append(problems, fmt.Sprintf("%s (%s): %v, add the image to rpms.exclude if it has no RPMs",
+					key, platformImage.Platform, err))
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s (%s): %w", key, platformImage.Platform, err))
+				continue
+			}
+			if inventory[key] == nil {
+				inventory[key] = make(map[string][]rpmdb.Package)
+			}
+			inventory[key][platformImage.Platform] = packages
+		}
+	}
+	return inventory, problems, errors.Join(errs...)
+}
+
+// DiffRPMBaseline compares the DefaultPlatform packages of the inventory with those of the same image keys in
+// the baseline snapshot images. Returns changes by image key; keys missing in the baseline are skipped.
+func DiffRPMBaseline(ctx context.Context, baselineImages map[string]string, inventory RPMInventory) (map[string][]rpmdb.Change, error) {
+	changes := make(map[string][]rpmdb.Change)
+	var errs []error
+	for _, key := range GetMapKeysSorted(inventory) {
+		current, ok := inventory[key][DefaultPlatform]
+		baselineImage := baselineImages[key]
+		if !ok || baselineImage == "" {
+			continue
+		}
+		baselineImage, err := baselinePlatformImage(ctx, baselineImage)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("baseline %s: %w", key, err))
+			continue
+		}
+		baseline, err := ReadImagePackages(ctx, baselineImage, DefaultPlatform)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("baseline %s: %w", key, err))
+			continue
+		}
+		if diff := rpmdb.Diff(baseline, current); len(diff) > 0 {
+			changes[key] = diff
+		}
+	}
+	return changes, errors.Join(errs...)
+}
+
+// baselinePlatformImage returns the DefaultPlatform image of a baseline manifest list or single-arch image.
+func baselinePlatformImage(ctx context.Context, imageDefinition string) (string, error) {
+	platformImages, err := ExpandManifestList(ctx, imageDefinition)
+	if err != nil {
+		return "", err
+	}
+	for _, platformImage := range platformImages {
+		if platformImage.Platform == DefaultPlatform {
+			return platformImage.Image, nil
+		}
+	}
+	return "", fmt.Errorf("no %s image in %s", DefaultPlatform, imageDefinition)
+}
+
+// FormatRPMInventory lists the number of packages per image and platform.
+func FormatRPMInventory(inventory RPMInventory) string {
+	var builder strings.Builder
+	table := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+	fmt.Fprintln(table, "IMAGE\tPLATFORM\tPACKAGES")
+	for _, key := range GetMapKeysSorted(inventory) {
+		for _, platform := range GetMapKeysSorted(inventory[key]) {
+			fmt.Fprintf(table, "%s\t%s\t%d\n", key, platform, len(inventory[key][platform]))
+		}
+	}
+	_ = table.Flush()
+	return builder.String()
+}
+
+// FormatRPMChanges lists package changes grouped by image.
+func FormatRPMChanges(changes map[string][]rpmdb.Change) string {
+	var builder strings.Builder
+	for _, key := range GetMapKeysSorted(changes) {
+		fmt.Fprintf(&builder, "%s:\n", key)
+		for _, change := range changes[key] {
+			fmt.Fprintf(&builder, "    %s\n", change)
+		}
+	}
+	return builder.String()
+}
+
+// WriteRPMInventory writes the inventory as indented JSON.
+func WriteRPMInventory(fileName string, inventory RPMInventory) error {
+	data, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode rpm inventory: %w", err)
+	}
+	if err := os.WriteFile(fileName, data, 0o600); err != nil { //nolint:mnd // file permissions
+		return fmt.Errorf("write rpm inventory: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package rpmdb
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Change is a package added, removed or updated between two package lists.
+type Change struct {
+	Name string `json:"name"`
+	Arch string `json:"arch,omitempty"`
+	// Old is the [epoch:]version-release before, empty for added packages.
+	Old string `json:"old,omitempty"`
+	// New is the [epoch:]version-release after, empty for removed packages.
+	New string `json:"new,omitempty"`
+}
+
+func (c Change) String() string {
+	name := c.Name
+	if c.Arch != "" {
+		name += "." + c.Arch
+	}
+	switch {
+	case c.Old == "":
+		return fmt.Sprintf("+ %s %s", name, c.New)
+	case c.New == "":
+		return fmt.Sprintf("- %s %s", name, c.Old)
+	default:
+		return fmt.Sprintf("~ %s %s -> %s", name, c.Old, c.New)
+	}
+}
+
+// Diff returns the changes from old to new packages by name and arch, sorted by name.
+func Diff(oldPackages, newPackages []Package) []Change {
+	oldVersions, newVersions := versionsByName(oldPackages), versionsByName(newPackages)
+	var changes []Change
+	for key, oldEVR := range oldVersions {
+		if newEVR := newVersions[key]; newEVR != oldEVR {
+			changes = append(changes, Change{Name: key[0], Arch: key[1], Old: oldEVR, New: newEVR})
+		}
+	}
+	for key, newEVR := range newVersions {
+		if _, ok := oldVersions[key]; !ok {
+			changes = append(changes, Change{Name: key[0], Arch: key[1], New: newEVR})
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int {
+		return strings.Compare(a.Name+"."+a.Arch, b.Name+"."+b.Arch)
+	})
+	return changes
+}
+
+// versionsByName maps name and arch to the EVRs installed, comma separated for multiple versions.
+func versionsByName(packages []Package) map[[2]string]string {
+	versions := make(map[[2]string]string)
+	for _, pkg := range packages {
+		key := [2]string{pkg.Name, pkg.Arch}
+		if versions[key] != "" {
+			versions[key] += ","
+		}
+		versions[key] += pkg.EVR()
+	}
+	return versions
+}
@@ -0,0 +1,168 @@
+// Package rpmdb reads the installed packages from an RPM database file without rpm or cgo.
+// Only the SQLite backend (rpmdb.sqlite, RHEL 9 and later) is supported, and changes not yet checkpointed
+// from a write-ahead log (rpmdb.sqlite-wal) are not seen.
+package rpmdb
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+)
+
+// Paths of the SQLite RPM database in an image filesystem, newest layout first.
+//
+//nolint:gochecknoglobals // read-only list of paths
+var DatabasePaths = []string{"/usr/lib/sysimage/rpm/rpmdb.sqlite", "/var/lib/rpm/rpmdb.sqlite"}
+
+// ErrUnsupportedDatabase is returned for databases other than SQLite, e.g. the Berkeley DB Packages file of RHEL 8.
+var ErrUnsupportedDatabase = errors.New("unsupported rpm database, only rpmdb.sqlite is supported")
+
+const (
+	packagesTable = "Packages"
+
+	tagName    = 1000
+	tagVersion = 1001
+	tagRelease = 1002
+	tagEpoch   = 1003
+	tagArch    = 1022
+
+	typeInt32       = 4
+	typeString      = 6
+	typeI18NString  = 9
+	headerEntrySize = 16
+	maxHeaderIndex  = 1 << 16
+)
+
+// Package is an installed RPM.
+type Package struct {
+	Name    string `json:"name"`
+	Epoch   int    `json:"epoch,omitempty"`
+	Version string `json:"version"`
+	Release string `json:"release"`
+	Arch    string `json:"arch,omitempty"`
+}
+
+// EVR returns [epoch:]version-release.
+func (p Package) EVR() string {
+	evr := p.Version + "-" + p.Release
+	if p.Epoch > 0 {
+		evr = strconv.Itoa(p.Epoch) + ":" + evr
+	}
+	return evr
+}
+
+// NEVRA returns name-[epoch:]version-release.arch, the way rpm -qa prints packages.
+func (p Package) NEVRA() string {
+	nevra := p.Name + "-" + p.EVR()
+	if p.Arch != "" {
+		nevra += "." + p.Arch
+	}
+	return nevra
+}
+
+func (p Package) String() string {
+	return p.NEVRA()
+}
+
+// ReadFile reads the packages of an rpmdb.sqlite file.
+func ReadFile(path string) ([]Package, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rpm database: %w", err)
+	}
+	return Read(data)
+}
+
+// Read returns the packages of an rpmdb.sqlite database sorted by name and arch.
+func Read(data []byte) ([]Package, error) {
+	db, err := openSQLite(data)
+	if err != nil {
+		return nil, err
+	}
+	root, err := db.tableRoot(packagesTable)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		packages []Package
+		parseErr error
+	)
+	err = db.walkTable(root, 0, func(record []any) bool {
+		// Packages columns: hnum (rowid alias, stored as NULL) and blob.
+		var blob []byte
+		if len(record) > 0 {
+			blob, _ = record[len(record)-1].([]byte)
+		}
+		if blob == nil {
+			parseErr = fmt.Errorf("%w: package record without header blob", errCorruptDatabase)
+			return false
+		}
+		pkg, err := parseHeader(blob)
+		if err != nil {
+			parseErr = err
+			return false
+		}
+		packages = append(packages, pkg)
+		return true
+	})
+	if err = errors.Join(err, parseErr); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(packages, func(a, b Package) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Arch, b.Arch), cmp.Compare(a.EVR(), b.EVR()))
+	})
+	return packages, nil
+}
+
+// parseHeader reads the package identity from an RPM header blob (index count, data size, index entries, data).
+func parseHeader(blob []byte) (Package, error) {
+	if len(blob) < 8 { //nolint:mnd // index count and data size
+		return Package{}, errors.New("rpm header too short")
+	}
+	indexCount := int(binary.BigEndian.Uint32(blob))
+	dataSize := int(binary.BigEndian.Uint32(blob[4:]))
+	dataStart := 8 + indexCount*headerEntrySize
+	if indexCount > maxHeaderIndex || dataSize > len(blob) || dataStart+dataSize > len(blob) {
+		return Package{}, errors.New("rpm header size out of range")
+	}
+	data := blob[dataStart : dataStart+dataSize]
+
+	var pkg Package
+	for index := range indexCount {
+		entry := blob[8+index*headerEntrySize:]
+		tag := binary.BigEndian.Uint32(entry)
+		dataType := binary.BigEndian.Uint32(entry[4:])
+		offset := int(binary.BigEndian.Uint32(entry[8:]))
+		if offset >= len(data) {
+			continue
+		}
+		switch {
+		case tag == tagEpoch && dataType == typeInt32 && offset+4 <= len(data):
+			pkg.Epoch = int(binary.BigEndian.Uint32(data[offset:]))
+		case dataType == typeString || dataType == typeI18NString:
+			value := data[offset:]
+			if end := bytes.IndexByte(value, 0); end >= 0 {
+				value = value[:end]
+			}
+			switch tag {
+			case tagName:
+				pkg.Name = string(value)
+			case tagVersion:
+				pkg.Version = string(value)
+			case tagRelease:
+				pkg.Release = string(value)
+			case tagArch:
+				pkg.Arch = string(value)
+			}
+		}
+	}
+	if pkg.Name == "" {
+		return Package{}, errors.New("rpm header without name")
+	}
+	return pkg, nil
+}
@@ -0,0 +1,13 @@
+package rpmdb_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRpmdb(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RPM Database Suite")
+}
@@ -0,0 +1,59 @@
+package rpmdb_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	testroot "github.com/securesign/structural-tests/test"
+	"github.com/securesign/structural-tests/test/support/rpmdb"
+)
+
+var _ = Describe("RPM database", func() {
+	var packages []rpmdb.Package
+
+	BeforeEach(func() {
+		var err error
+		packages, err = rpmdb.ReadFile(filepath.Join(testroot.GetRootPath(), "testdata", "rpmdb", "rpmdb.sqlite"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("reads all packages across b-tree pages", func() {
+		Expect(packages).To(HaveLen(304))
+		Expect(packages[0].NEVRA()).To(Equal("bash-5.1.8-9.el9.x86_64"))
+	})
+
+	It("reads epochs and packages without arch", func() {
+		Expect(packages).To(ContainElement(rpmdb.Package{Name: "openssl-libs", Epoch: 1, Version: "3.0.7", Release: "27.el9", Arch: "x86_64"}))
+		Expect(packages).To(ContainElement(rpmdb.Package{Name: "gpg-pubkey", Version: "fd431d51", Release: "4ae0493b"}))
+	})
+
+	It("reads headers stored on overflow pages", func() {
+		Expect(packages).To(ContainElement(HaveField("Name", "glibc-all-langpacks")))
+	})
+
+	It("rejects other database formats", func() {
+		_, err := rpmdb.Read(make([]byte, 4096))
+		Expect(err).To(MatchError(rpmdb.ErrUnsupportedDatabase))
+	})
+})
+
+var _ = Describe("Package diff", func() {
+	It("reports added, removed and updated packages", func() {
+		oldPackages := []rpmdb.Package{
+			{Name: "bash", Version: "5.1.8", Release: "6.el9", Arch: "x86_64"},
+			{Name: "strace", Version: "5.18", Release: "2.el9", Arch: "x86_64"},
+			{Name: "zlib", Version: "1.2.11", Release: "40.el9", Arch: "x86_64"},
+		}
+		newPackages := []rpmdb.Package{
+			{Name: "bash", Version: "5.1.8", Release: "9.el9", Arch: "x86_64"},
+			{Name: "gcc", Version: "11.4.1", Release: "3.el9", Arch: "x86_64"},
+			{Name: "zlib", Version: "1.2.11", Release: "40.el9", Arch: "x86_64"},
+		}
+		changes := rpmdb.Diff(oldPackages, newPackages)
+		Expect(changes).To(HaveLen(3))
+		Expect(changes[0].String()).To(Equal("~ bash.x86_64 5.1.8-6.el9 -> 5.1.8-9.el9"))
+		Expect(changes[1].String()).To(Equal("+ gcc.x86_64 11.4.1-3.el9"))
+		Expect(changes[2].String()).To(Equal("- strace.x86_64 5.18-2.el9"))
+	})
+})
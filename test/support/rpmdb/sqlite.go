@@ -0,0 +1,290 @@
+package rpmdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Read-only access to table b-trees of an SQLite 3 database file, enough to read the rpmdb.sqlite Packages table.
+// See https://www.sqlite.org/fileformat.html.
+
+const (
+	sqliteMagic        = "SQLite format 3\x00"
+	sqliteHeaderSize   = 100
+	sqliteMaxPageSize  = 65536
+	sqliteMaxTreeDepth = 64
+
+	pageInteriorTable = 0x05
+	pageLeafTable     = 0x0d
+
+	leafHeaderSize     = 8
+	interiorHeaderSize = 12
+
+	varintMaxBytes = 9
+)
+
+var errCorruptDatabase = errors.New("corrupt sqlite database")
+
+type sqliteDB struct {
+	data       []byte
+	pageSize   int
+	usableSize int
+}
+
+func openSQLite(data []byte) (*sqliteDB, error) {
+	if len(data) < sqliteHeaderSize || string(data[:len(sqliteMagic)]) != sqliteMagic {
+		return nil, ErrUnsupportedDatabase
+	}
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = sqliteMaxPageSize
+	}
+	usableSize := pageSize - int(data[20])
+	if pageSize < 512 || usableSize < 480 { //nolint:mnd // minimal sizes of the file format
+		return nil, fmt.Errorf("%w: page size %d", errCorruptDatabase, pageSize)
+	}
+	return &sqliteDB{data: data, pageSize: pageSize, usableSize: usableSize}, nil
+}
+
+func (db *sqliteDB) page(number uint32) ([]byte, error) {
+	start := (int64(number) - 1) * int64(db.pageSize)
+	if number == 0 || start+int64(db.pageSize) > int64(len(db.data)) {
+		return nil, fmt.Errorf("%w: page %d out of range", errCorruptDatabase, number)
+	}
+	return db.data[start : start+int64(db.pageSize)], nil
+}
+
+// tableRoot returns the root page of a table from the sqlite_schema table on page 1.
+func (db *sqliteDB) tableRoot(table string) (uint32, error) {
+	var root int64
+	err := db.walkTable(1, 0, func(record []any) bool {
+		//nolint:mnd // sqlite_schema columns: type, name, tbl_name, rootpage, sql
+		if len(record) >= 4 && record[0] == "table" && record[1] == table {
+			root, _ = record[3].(int64)
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	if root <= 0 || root > math.MaxUint32 {
+		return 0, fmt.Errorf("table %s not found", table)
+	}
+	return uint32(root), nil
+}
+
+// walkTable calls visit with the record of every row of the table b-tree rooted at page, until visit returns false.
+func (db *sqliteDB) walkTable(number uint32, depth int, visit func(record []any) bool) error {
+	_, err := db.walk(number, depth, visit)
+	return err
+}
+
+func (db *sqliteDB) walk(number uint32, depth int, visit func(record []any) bool) (bool, error) {
+	if depth > sqliteMaxTreeDepth {
+		return false, fmt.Errorf("%w: b-tree too deep", errCorruptDatabase)
+	}
+	page, err := db.page(number)
+	if err != nil {
+		return false, err
+	}
+	headerStart := 0
+	if number == 1 {
+		headerStart = sqliteHeaderSize
+	}
+	if len(page) < headerStart+interiorHeaderSize {
+		return false, fmt.Errorf("%w: page %d too small", errCorruptDatabase, number)
+	}
+	cells := int(binary.BigEndian.Uint16(page[headerStart+3:]))
+
+	switch page[headerStart] {
+	case pageLeafTable:
+		for index := range cells {
+			pointer, err := cellPointer(page, headerStart+leafHeaderSize, index)
+			if err != nil {
+				return false, err
+			}
+			payload, err := db.leafPayload(page, pointer)
+			if err != nil {
+				return false, err
+			}
+			record, err := parseRecord(payload)
+			if err != nil {
+				return false, err
+			}
+			if !visit(record) {
+				return false, nil
+			}
+		}
+	case pageInteriorTable:
+		for index := range cells {
+			pointer, err := cellPointer(page, headerStart+interiorHeaderSize, index)
+			if err != nil {
+				return false, err
+			}
+			if pointer+4 > len(page) {
+				return false, fmt.Errorf("%w: cell out of page %d", errCorruptDatabase, number)
+			}
+			if more, err := db.walk(binary.BigEndian.Uint32(page[pointer:]), depth+1, visit); err != nil || !more {
+				return more, err
+			}
+		}
+		return db.walk(binary.BigEndian.Uint32(page[headerStart+leafHeaderSize:]), depth+1, visit)
+	default:
+		return false, fmt.Errorf("%w: page %d is not a table b-tree page", errCorruptDatabase, number)
+	}
+	return true, nil
+}
+
+func cellPointer(page []byte, arrayStart, index int) (int, error) {
+	offset := arrayStart + 2*index
+	if offset+2 > len(page) {
+		return 0, fmt.Errorf("%w: cell pointer out of page", errCorruptDatabase)
+	}
+	pointer := int(binary.BigEndian.Uint16(page[offset:]))
+	if pointer >= len(page) {
+		return 0, fmt.Errorf("%w: cell out of page", errCorruptDatabase)
+	}
+	return pointer, nil
+}
+
+// leafPayload returns the payload of a table leaf cell, following overflow pages.
+func (db *sqliteDB) leafPayload(page []byte, pointer int) ([]byte, error) {
+	payloadSize, n := readVarint(page[pointer:])
+	if n == 0 {
+		return nil, fmt.Errorf("%w: invalid cell", errCorruptDatabase)
+	}
+	_, m := readVarint(page[pointer+n:]) // rowid
+	if m == 0 || payloadSize > uint64(len(db.data)) {
+		return nil, fmt.Errorf("%w: invalid cell", errCorruptDatabase)
+	}
+	start := pointer + n + m
+	local := db.localPayloadSize(int(payloadSize))
+	if start+local > len(page) {
+		return nil, fmt.Errorf("%w: payload out of page", errCorruptDatabase)
+	}
+	payload := make([]byte, 0, payloadSize)
+	payload = append(payload, page[start:start+local]...)
+	if local == int(payloadSize) {
+		return payload, nil
+	}
+	if start+local+4 > len(page) {
+		return nil, fmt.Errorf("%w: overflow pointer out of page", errCorruptDatabase)
+	}
+	next := binary.BigEndian.Uint32(page[start+local:])
+	for len(payload) < int(payloadSize) {
+		overflow, err := db.page(next)
+		if err != nil {
+			return nil, err
+		}
+		chunk := overflow[4:db.usableSize]
+		if remaining := int(payloadSize) - len(payload); len(chunk) > remaining {
+			chunk = chunk[:remaining]
+		}
+		payload = append(payload, chunk...)
+		next = binary.BigEndian.Uint32(overflow)
+	}
+	return payload, nil
+}
+
+// localPayloadSize is the part of a table leaf payload stored on the b-tree page.
+func (db *sqliteDB) localPayloadSize(payloadSize int) int {
+	maxLocal := db.usableSize - 35             //nolint:mnd // file format constant
+	minLocal := (db.usableSize-12)*32/255 - 23 //nolint:mnd // file format constant
+	if payloadSize <= maxLocal {
+		return payloadSize
+	}
+	local := minLocal + (payloadSize-minLocal)%(db.usableSize-4)
+	if local <= maxLocal {
+		return local
+	}
+	return minLocal
+}
+
+// parseRecord decodes a record into nil, int64, float64, string or []byte values.
+func parseRecord(payload []byte) ([]any, error) {
+	headerSize, n := readVarint(payload)
+	if n == 0 || headerSize > uint64(len(payload)) {
+		return nil, fmt.Errorf("%w: invalid record header", errCorruptDatabase)
+	}
+	var serialTypes []uint64
+	for offset := n; offset < int(headerSize); {
+		serialType, m := readVarint(payload[offset:int(headerSize)])
+		if m == 0 {
+			return nil, fmt.Errorf("%w: invalid record header", errCorruptDatabase)
+		}
+		serialTypes = append(serialTypes, serialType)
+		offset += m
+	}
+	body := payload[headerSize:]
+	record := make([]any, 0, len(serialTypes))
+	for _, serialType := range serialTypes {
+		size := serialTypeSize(serialType)
+		if size > len(body) {
+			return nil, fmt.Errorf("%w: record value out of payload", errCorruptDatabase)
+		}
+		record = append(record, decodeValue(serialType, body[:size]))
+		body = body[size:]
+	}
+	return record, nil
+}
+
+func serialTypeSize(serialType uint64) int {
+	switch {
+	case serialType >= 12: //nolint:mnd // blob (even) or text (odd)
+		return int((serialType - 12) / 2) //nolint:mnd // file format constant
+	case serialType == 5: //nolint:mnd // 48-bit integer
+		return 6
+	case serialType == 6 || serialType == 7: //nolint:mnd // 64-bit integer or float
+		return 8
+	case serialType >= 1 && serialType <= 4: //nolint:mnd // 8 to 32-bit integers
+		return int(serialType)
+	default: // NULL, constants 0 and 1, reserved
+		return 0
+	}
+}
+
+func decodeValue(serialType uint64, value []byte) any {
+	switch {
+	case serialType == 0:
+		return nil
+	case serialType == 8: //nolint:mnd // constant 0
+		return int64(0)
+	case serialType == 9: //nolint:mnd // constant 1
+		return int64(1)
+	case serialType == 7: //nolint:mnd // IEEE 754 float
+		return math.Float64frombits(binary.BigEndian.Uint64(value))
+	case serialType >= 12 && serialType%2 == 0:
+		return append([]byte(nil), value...)
+	case serialType >= 13: //nolint:mnd // text
+		return string(value)
+	case serialType <= 6: //nolint:mnd // big-endian two's complement integers
+		var result int64
+		for _, b := range value {
+			result = result<<8 | int64(b)
+		}
+		if len(value) > 0 && value[0]&0x80 != 0 && len(value) < 8 {
+			result -= 1 << (8 * len(value))
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// readVarint decodes an SQLite varint, returning 0 bytes read when data is too short.
+func readVarint(data []byte) (uint64, int) {
+	var result uint64
+	for index := 0; index < varintMaxBytes && index < len(data); index++ {
+		if index == varintMaxBytes-1 {
+			return result<<8 | uint64(data[index]), varintMaxBytes
+		}
+		result = result<<7 | uint64(data[index]&0x7f)
+		if data[index]&0x80 == 0 {
+			return result, index + 1
+		}
+	}
+	return 0, 0
+}
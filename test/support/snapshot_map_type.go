@@ -48,6 +48,9 @@ func extractImages(rawData map[string]interface{}, snapshotData SnapshotData) {
 						}
 					}
 				}
+			} else if key == "artifact-signer-ansible" {
+				// Legacy format: "artifact-signer-ansible": { "collection": { "url": "...", "sha256": "..." } }
+				extractAnsibleCollection(valueType, snapshotData)
 			} else {
 				extractImages(valueType, snapshotData)
 			}
@@ -55,6 +58,22 @@ func extractImages(rawData map[string]interface{}, snapshotData SnapshotData) {
 	}
 }
 
+func extractAnsibleCollection(rawData map[string]interface{}, snapshotData SnapshotData) {
+	collection, ok := rawData["collection"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for field, snapshotKey := range map[string]string{
+		"image":  AnsibleCollectionImageKey,
+		"url":    AnsibleCollectionURLKey,
+		"sha256": AnsibleCollectionSHA256Key,
+	} {
+		if value, ok := collection[field].(string); ok && value != "" {
+			snapshotData.Others[snapshotKey] = value
+		}
+	}
+}
+
 func isImageDefinition(snapshotKey string) bool {
 	return imageRegexp.MatchString(snapshotKey)
 }
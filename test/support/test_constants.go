@@ -1,15 +1,26 @@
 package support
 
+import "strings"
+
 const (
 	EnvReleasesSnapshotFile = "SNAPSHOT"
 	EnvRepositoriesFile     = "REPOSITORIES"
 	EnvTestGithubToken      = "TEST_GITHUB_TOKEN" // #nosec G101
 	EnvVersion              = "VERSION"
 	EnvTestConfig           = "TEST_CONFIG"
+	EnvAnsibleCollection    = "ANSIBLE_COLLECTION"
+	EnvReleasePhase         = "RELEASE_PHASE"
+	EnvGitMirrors           = "GIT_MIRRORS"
+	EnvRPMInventory         = "RPM_INVENTORY"
+	EnvRPMBaselineSnapshot  = "RPM_BASELINE_SNAPSHOT"
+
+	ReleasePhasePostGA = "post-ga"
 
 	OperatorImageKey             = "rhtas-operator-image"
 	OperatorBundleImageKey       = "rhtas-operator-bundle-image"
 	AnsibleCollectionImageKey    = "artifact-signer-ansible.collection.image"
+	AnsibleCollectionURLKey      = "artifact-signer-ansible.collection.url"
+	AnsibleCollectionSHA256Key   = "artifact-signer-ansible.collection.sha256"
 	AnsibleCollectionPathInImage = "/releases"
 
 	AnsibleCollectionSnapshotFile = "roles/tas_single_node/defaults/main.yml"
@@ -19,6 +30,7 @@ const (
 	SnapshotImageDefinitionRegexp = `^[\.\w/-]+@sha256:\w{64}$`
 
 	DefaultRepositoriesFile = "testdata/repositories.json"
+	PyxisRepositoriesSource = "pyxis"
 )
 
 type OSArchMatrix map[string][]string
@@ -31,10 +43,32 @@ func GetOSArchMatrix() OSArchMatrix {
 	}
 }
 
+// ArchitectureLabels maps OCI platform architectures to the value of the architecture image label.
+func ArchitectureLabels() map[string]string {
+	return map[string]string{
+		"amd64":   "x86_64",
+		"arm64":   "aarch64",
+		"ppc64le": "ppc64le",
+		"s390x":   "s390x",
+	}
+}
+
 // If no value is provided, the label must exist, but can have any non-empty value.
 func RequiredImageLabels() map[string]string {
+	return RequiredImageLabelsForPlatform(DefaultPlatform)
+}
+
+// RequiredImageLabelsForPlatform returns RequiredImageLabels with the architecture expected for platform (os/arch).
+func RequiredImageLabelsForPlatform(platform string) map[string]string {
+	architecture := platform
+	if parts := strings.Split(platform, "/"); len(parts) > 1 {
+		architecture = parts[1]
+	}
+	if label, ok := ArchitectureLabels()[architecture]; ok {
+		architecture = label
+	}
 	return map[string]string{
-		"architecture": "x86_64",
+		"architecture": architecture,
 		"build-date":   "",
 		"vcs-ref":      "",
 		"vcs-type":     "git",
@@ -0,0 +1,222 @@
+package support
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	versionLabel = "version"
+	releaseLabel = "release"
+	// versionPatternSample replaces placeholders when patterns are validated.
+	versionPatternSample = "1.0.0"
+)
+
+// VersionPolicy configures the version label check (versions section of the suite config).
+type VersionPolicy struct {
+	// Version is the expected version label of product images, a template with {version}, {minor} and {major}.
+	Version string `yaml:"version"`
+	// Release is an optional regular expression the release label must match.
+	Release string `yaml:"release"`
+	// Components are images following their own version scheme; the first one matching the image key applies.
+	Components []VersionScheme `yaml:"components"`
+	// Exceptions are images not versioned with the product, e.g. third-party or independently versioned components.
+	Exceptions []VersionException `yaml:"exceptions"`
+}
+
+// VersionScheme is the version scheme of some images.
+type VersionScheme struct {
+	// Images are image keys (path.Match patterns).
+	Images []string `yaml:"images"`
+	// Version is the expected version label, a template like VersionPolicy.Version.
+	Version string `yaml:"version"`
+	// Pattern is a regular expression the version label must match instead of Version, may contain the placeholders.
+	Pattern string `yaml:"pattern"`
+	// Release overrides VersionPolicy.Release.
+	Release string `yaml:"release"`
+	// Reason documents the scheme.
+	Reason string `yaml:"reason"`
+}
+
+// VersionException excludes images from the version label check.
+type VersionException struct {
+	// Image is an image key (path.Match pattern).
+	Image  string `yaml:"image"`
+	Reason string `yaml:"reason"`
+}
+
+// VersionDeviation is a version or release label of an image not following its scheme.
+type VersionDeviation struct {
+	Key      string
+	Platform string
+	Label    string
+	Value    string
+	Expected string
+}
+
+func (d VersionDeviation) String() string {
+	return fmt.Sprintf("%s (%s): %s %q, expected %s", d.Key, d.Platform, d.Label, d.Value, d.Expected)
+}
+
+// DefaultVersionPolicy expects the version label of every image to equal VERSION.
+func DefaultVersionPolicy() VersionPolicy {
+	return VersionPolicy{Version: "{version}"}
+}
+
+// Validate checks that components have images and a version or pattern, and that patterns are valid regular expressions.
+func (p VersionPolicy) Validate() error {
+	if p.Version == "" {
+		return errors.New("missing version")
+	}
+	if err := validateVersionPattern(p.Release); err != nil {
+		return fmt.Errorf("release: %w", err)
+	}
+	for index, scheme := range p.Components {
+		if len(scheme.Images) == 0 {
+			return fmt.Errorf("component %d: missing images", index)
+		}
+		if (scheme.Version == "") == (scheme.Pattern == "") {
+			return fmt.Errorf("component %s: exactly one of version and pattern is required", scheme.Images[0])
+		}
+		for _, pattern := range []string{scheme.Pattern, scheme.Release} {
+			if err := validateVersionPattern(pattern); err != nil {
+				return fmt.Errorf("component %s: %w", scheme.Images[0], err)
+			}
+		}
+	}
+	for _, exception := range p.Exceptions {
+		if _, err := path.Match(exception.Image, ""); err != nil || exception.Image == "" {
+			return fmt.Errorf("invalid exception image %q", exception.Image)
+		}
+	}
+	return nil
+}
+
+func validateVersionPattern(pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	if _, err := regexp.Compile(expandVersionPattern(pattern, versionPatternSample)); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// Exception returns the exception of the image key, nil if it has none.
+func (p VersionPolicy) Exception(key string) *VersionException {
+	for index, exception := range p.Exceptions {
+		if matched, err := path.Match(exception.Image, key); err == nil && matched {
+			return &p.Exceptions[index]
+		}
+	}
+	return nil
+}
+
+// SchemeFor returns the version scheme of the image key, the product scheme when no component matches.
+func (p VersionPolicy) SchemeFor(key string) VersionScheme {
+	scheme := VersionScheme{Version: p.Version}
+	for _, component := range p.Components {
+		if matchesAnyPattern(component.Images, key) {
+			scheme = component
+			break
+		}
+	}
+	if scheme.Release == "" {
+		scheme.Release = p.Release
+	}
+	return scheme
+}
+
+func matchesAnyPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckVersionLabels returns deviations of the version and release labels of an image from its scheme.
+func (p VersionPolicy) CheckVersionLabels(key, platform, version string, labels map[string]string) []VersionDeviation {
+	scheme := p.SchemeFor(key)
+	var deviations []VersionDeviation
+	deviation := func(label, expected string) {
+		deviations = append(deviations, VersionDeviation{
+			Key: key, Platform: platform, Label: label, Value: labels[label], Expected: expected,
+		})
+	}
+
+	if scheme.Pattern != "" {
+		pattern := expandVersionPattern(scheme.Pattern, version)
+		if matched, err := regexp.MatchString(pattern, labels[versionLabel]); err != nil || !matched {
+			deviation(versionLabel, "to match "+pattern)
+		}
+	} else if expected := ExpandReleaseTags([]string{scheme.Version}, version)[0]; labels[versionLabel] != expected {
+		deviation(versionLabel, expected)
+	}
+	if scheme.Release != "" {
+		pattern := expandVersionPattern(scheme.Release, version)
+		if matched, err := regexp.MatchString(pattern, labels[releaseLabel]); err != nil || !matched {
+			deviation(releaseLabel, "to match "+pattern)
+		}
+	}
+	return deviations
+}
+
+// expandVersionPattern replaces the version placeholders of a regular expression with quoted parts of version.
+func expandVersionPattern(pattern, version string) string {
+	minor, major := versionParts(version)
+	return strings.NewReplacer("{version}", regexp.QuoteMeta(version), "{minor}", regexp.QuoteMeta(minor),
+		"{major}", regexp.QuoteMeta(major)).Replace(pattern)
+}
+
+// CheckSnapshotVersions checks the version labels of every platform of the snapshot images against the policy.
+// Images with an exception are skipped and returned by key with the reason.
+func CheckSnapshotVersions(ctx context.Context, images map[string]string, policy VersionPolicy,
+	version string) ([]VersionDeviation, map[string]string, error) {
+	if version == "" {
+		return nil, nil, errors.New("VERSION is required to check version labels")
+	}
+	var deviations []VersionDeviation
+	skipped := make(map[string]string)
+	var errs []error
+	for _, key := range GetMapKeysSorted(images) {
+		if exception := policy.Exception(key); exception != nil {
+			skipped[key] = exception.Reason
+			continue
+		}
+		platformImages, err := ExpandManifestList(ctx, images[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		for _, platformImage := range platformImages {
+			labels, err := InspectImageForLabelsOnPlatform(platformImage.Image, platformImage.Platform)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to inspect labels for image %s (%s, %s): %w",
+					key, platformImage.Image, platformImage.Platform, err))
+				continue
+			}
+			deviations = append(deviations, policy.CheckVersionLabels(key, platformImage.Platform, version, labels)...)
+		}
+	}
+	return deviations, skipped, errors.Join(errs...)
+}
+
+// FormatVersionDeviations lists deviations as an aligned table.
+func FormatVersionDeviations(deviations []VersionDeviation) string {
+	var builder strings.Builder
+	table := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+	fmt.Fprintln(table, "IMAGE\tPLATFORM\tLABEL\tVALUE\tEXPECTED")
+	for _, deviation := range deviations {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%q\t%s\n",
+			deviation.Key, deviation.Platform, deviation.Label, deviation.Value, deviation.Expected)
+	}
+	_ = table.Flush()
+	return builder.String()
+}
@@ -0,0 +1,63 @@
+package support_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/securesign/structural-tests/test/support"
+)
+
+var _ = Describe("Version labels", func() {
+	policy := support.VersionPolicy{
+		Version: "{version}",
+		Release: `^\d+$`,
+		Components: []support.VersionScheme{
+			{Images: []string{"trillian-*"}, Version: "{minor}", Reason: "versioned by minor"},
+			{Images: []string{"trillian-db-image", "tuf-*"}, Pattern: `^{major}\.\d+$`, Release: `^\d+\.el9$`},
+		},
+	}
+
+	DescribeTable("selects the version scheme",
+		func(key string, version, pattern, release string) {
+			scheme := policy.SchemeFor(key)
+			Expect(scheme.Version).To(Equal(version))
+			Expect(scheme.Pattern).To(Equal(pattern))
+			Expect(scheme.Release).To(Equal(release))
+		},
+		Entry("product scheme", "rekor-server-image", "{version}", "", `^\d+$`),
+		Entry("component scheme inherits the product release", "trillian-logserver-image", "{minor}", "", `^\d+$`),
+		Entry("first matching component takes precedence", "trillian-db-image", "{minor}", "", `^\d+$`),
+		Entry("component release overrides the product release", "tuf-server-image", "", `^{major}\.\d+$`, `^\d+\.el9$`),
+	)
+
+	DescribeTable("quotes placeholders of version patterns",
+		func(pattern, version, expected string) {
+			Expect(support.ExpandVersionPattern(pattern, version)).To(Equal(expected))
+		},
+		Entry("version", `^{version}$`, "1.2.0", `^1\.2\.0$`),
+		Entry("minor and major", `^{minor}\.\d+-{major}$`, "v1.2.0", `^1\.2\.\d+-1$`),
+		Entry("major only version", `^{minor}$`, "2", `^2$`),
+		Entry("regular expression characters in the version", `^{version}$`, "1.2.0+rc(1)", `^1\.2\.0\+rc\(1\)$`),
+		Entry("no placeholders", `^\d+$`, "1.2.0", `^\d+$`),
+	)
+
+	DescribeTable("checks version and release labels",
+		func(key string, labels map[string]string, expected ...string) {
+			var deviations []string
+			for _, deviation := range policy.CheckVersionLabels(key, "linux/amd64", "1.2.0", labels) {
+				deviations = append(deviations, deviation.String())
+			}
+			Expect(deviations).To(HaveExactElements(expected))
+		},
+		Entry("product version", "rekor-server-image", map[string]string{"version": "1.2.0", "release": "3"}),
+		Entry("wrong product version and release", "rekor-server-image", map[string]string{"version": "1.2", "release": "3.el9"},
+			`rekor-server-image (linux/amd64): version "1.2", expected 1.2.0`,
+			`rekor-server-image (linux/amd64): release "3.el9", expected to match ^\d+$`),
+		Entry("component version", "trillian-logserver-image", map[string]string{"version": "1.2", "release": "3"}),
+		Entry("component pattern and release", "tuf-server-image", map[string]string{"version": "1.7", "release": "3.el9"}),
+		Entry("component pattern mismatch", "tuf-server-image", map[string]string{"version": "2.0", "release": "3.el9"},
+			`tuf-server-image (linux/amd64): version "2.0", expected to match ^1\.\d+$`),
+		Entry("missing labels", "rekor-server-image", map[string]string{},
+			`rekor-server-image (linux/amd64): version "", expected 1.2.0`,
+			`rekor-server-image (linux/amd64): release "", expected to match ^\d+$`),
+	)
+})